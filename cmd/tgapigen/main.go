@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/baldisbk/tgbot/internal/tgapigen"
+)
+
+func main() {
+	specPath := flag.String("spec", "spec/botapi.json", "Bot API spec file")
+	outPath := flag.String("out", "generated.go", "output Go file")
+	pkg := flag.String("package", os.Getenv("GOPACKAGE"), "output package name")
+	flag.Parse()
+
+	if err := run(*specPath, *outPath, *pkg); err != nil {
+		fmt.Fprintf(os.Stderr, "tgapigen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(specPath, outPath, pkg string) error {
+	if pkg == "" {
+		return fmt.Errorf("no package, run with go generate or set -package")
+	}
+	file, err := os.Open(specPath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	spec, err := tgapigen.Parse(file)
+	if err != nil {
+		return err
+	}
+	src, err := tgapigen.Generate(spec, pkg, filepath.ToSlash(specPath))
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(outPath, src, 0644)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/baldisbk/tgbot/pkg/poller"
 	"github.com/baldisbk/tgbot/pkg/tgapi"
 	"github.com/baldisbk/tgbot/pkg/timer"
+	"github.com/baldisbk/tgbot/pkg/webhook"
 )
 
 func main() {
@@ -25,7 +26,7 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	signals := make(chan os.Signal)
+	signals := make(chan os.Signal, 1)
 	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
 
 	logger, err := zap.NewDevelopment()
@@ -40,47 +41,68 @@ func main() {
 
 	logging.S(ctx).Debugf("Parsing config...")
 
-	config, err := config.ParseConfig()
+	cfg, err := config.ParseConfig()
 	if err != nil {
 		logging.S(ctx).Errorf("Read config: %#v", err)
 		os.Exit(1)
 	}
-	logging.S(ctx).Debugf("Config: %#v", config)
+	logging.S(ctx).Debugf("Config: %#v", cfg)
 
 	logging.S(ctx).Debugf("Init TG client...")
 
-	tgClient, err := tgapi.NewClient(ctx, config.ApiConfig)
+	tgClient, err := tgapi.NewClient(ctx, cfg.ApiConfig)
 	if err != nil {
 		logging.S(ctx).Errorf("TG client: %#v", err)
 		os.Exit(1)
 	}
 
+	logging.S(ctx).Debugf("Bot: @%s (%d)", tgClient.BotInfo().Username, tgClient.BotInfo().Id)
+
+	if err := tgClient.SetMyCommands(ctx, impl.Commands, nil, ""); err != nil {
+		logging.S(ctx).Errorf("Register commands: %#v", err)
+	}
+
 	logging.S(ctx).Debugf("Init database...")
 
-	cache, err := usercache.NewCache(ctx, config.CacheConfig)
+	cache, err := usercache.NewCache(ctx, cfg.CacheConfig)
 	if err != nil {
 		logging.S(ctx).Errorf("DB client: %#v", err)
 		os.Exit(1)
 	}
 	defer cache.Close()
 
-	eng := engine.NewEngine(tgClient, cache)
+	eng := engine.NewEngine(cfg.EngineConfig, tgClient, cache)
 
 	logging.S(ctx).Debugf("Starting timers...")
 
-	tim := timer.NewTimer(ctx, config.TimerConfig, eng)
+	tim := timer.NewTimer(ctx, cfg.TimerConfig, eng)
 	defer tim.Shutdown()
 
-	factory := impl.NewFactory(config.FactoryConfig, tgClient, tim)
+	factory := impl.NewFactory(cfg.FactoryConfig, tgClient, tim, cache)
 	if err := cache.AttachFactory(ctx, factory); err != nil {
 		logging.S(ctx).Errorf("attach factory: %#v", err)
 		os.Exit(1)
 	}
 
-	logging.S(ctx).Debugf("Starting poller...")
-
-	poll := poller.NewPoller(ctx, config.PollerConfig, tgClient, eng)
-	defer poll.Shutdown()
+	switch cfg.Mode {
+	case "", config.PollerMode:
+		logging.S(ctx).Debugf("Starting poller...")
+
+		poll := poller.NewPoller(ctx, cfg.PollerConfig, tgClient, eng)
+		defer poll.Shutdown()
+	case config.WebhookMode:
+		logging.S(ctx).Debugf("Starting webhook at %q...", cfg.WebhookConfig.Address)
+
+		hook, err := webhook.NewWebhook(ctx, cfg.WebhookConfig, tgClient, eng)
+		if err != nil {
+			logging.S(ctx).Errorf("Webhook: %#v", err)
+			os.Exit(1)
+		}
+		defer hook.Shutdown()
+	default:
+		logging.S(ctx).Errorf("Unknown mode: %q", cfg.Mode)
+		os.Exit(1)
+	}
 
 	logging.S(ctx).Debugf("Bot started")
 
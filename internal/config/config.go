@@ -6,10 +6,12 @@ import (
 
 	"github.com/baldisbk/tgbot/internal/impl"
 	"github.com/baldisbk/tgbot/internal/usercache"
+	"github.com/baldisbk/tgbot/pkg/engine"
 	"github.com/baldisbk/tgbot/pkg/envconfig"
 	"github.com/baldisbk/tgbot/pkg/poller"
 	"github.com/baldisbk/tgbot/pkg/tgapi"
 	"github.com/baldisbk/tgbot/pkg/timer"
+	"github.com/baldisbk/tgbot/pkg/webhook"
 
 	"golang.org/x/xerrors"
 	"gopkg.in/yaml.v3"
@@ -20,6 +22,12 @@ var (
 	develPath   = "config.yaml"
 )
 
+// update receiving modes
+const (
+	PollerMode  = "poller"
+	WebhookMode = "webhook"
+)
+
 var configPath = flag.String("config", "", "path to config")
 var develMode = flag.Bool("devel", false, "development mode")
 
@@ -31,9 +39,13 @@ type ConfigFlags struct {
 type Config struct {
 	ConfigFlags
 
+	Mode string `yaml:"mode" env:"TGBOT_MODE"`
+
+	EngineConfig  engine.Config    `yaml:"engine"`
 	CacheConfig   usercache.Config `yaml:"user_cache"`
 	FactoryConfig impl.Config      `yaml:"user_factory"`
 	PollerConfig  poller.Config    `yaml:"poller"`
+	WebhookConfig webhook.Config   `yaml:"webhook"`
 	TimerConfig   timer.Config     `yaml:"timer"`
 	ApiConfig     tgapi.Config     `yaml:"tgapi"`
 }
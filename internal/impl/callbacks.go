@@ -5,25 +5,62 @@ import (
 	"fmt"
 	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/baldisbk/tgbot/pkg/format"
+	"github.com/baldisbk/tgbot/pkg/logging"
 	"github.com/baldisbk/tgbot/pkg/tgapi"
 	"github.com/baldisbk/tgbot/pkg/timer"
 	"golang.org/x/xerrors"
 )
 
-const listLength = 5
+const (
+	listLength = 5
+	// telegram allows up to 50
+	inlineLength = 20
+	// seconds, progress changes often
+	inlineCacheTime = 10
+)
 
-func (u *User) ask(ctx context.Context, message string, options []tgapi.InlineKeyboardButton) (interface{}, error) {
-	if msgId, err := u.tgClient.EditInputKeyboard(ctx, u.Id, message, u.lastMessage,
+func (u *User) ask(ctx context.Context, message tgapi.Text, options []tgapi.InlineKeyboardButton) (interface{}, error) {
+	if msgId, err := u.tgClient.EditInputKeyboard(ctx, u.recipient(), message, u.lastMessage,
 		tgapi.InlineKeyboard{InlineKeyboard: [][]tgapi.InlineKeyboardButton{options}}); err != nil {
 		return nil, xerrors.Errorf("send: %w", err)
 	} else {
 		u.lastMessage = msgId
 	}
+	u.dropNoise(ctx)
 	return nil, nil
 }
 
+// answer shows toast or alert on button press, other inputs are ignored
+func answer(input interface{}, answer tgapi.AnswerCallback) {
+	if query, ok := input.(*tgapi.CallbackQuery); ok {
+		query.SetAnswer(answer)
+	}
+}
+
+// cleanup drops keyboard of menu and noise, errors are ignored as it's cosmetic
+func (u *User) cleanup(ctx context.Context) {
+	if u.lastMessage != 0 {
+		if err := u.tgClient.EditReplyMarkup(ctx, u.recipient(), u.lastMessage, nil); err != nil {
+			logging.S(ctx).Warnf("Drop keyboard: %s", err)
+		}
+	}
+	u.dropNoise(ctx)
+}
+
+func (u *User) dropNoise(ctx context.Context) {
+	if u.noise == 0 {
+		return
+	}
+	if err := u.tgClient.DeleteMessage(ctx, u.recipient(), u.noise); err != nil {
+		logging.S(ctx).Warnf("Delete noise: %s", err)
+	}
+	u.noise = 0
+}
+
 func (u *User) doNoUnderstand(ctx context.Context, input interface{}) (interface{}, error) {
 	message := fmt.Sprintf("Can't understand you")
 	switch input.(type) {
@@ -33,11 +70,26 @@ func (u *User) doNoUnderstand(ctx context.Context, input interface{}) (interface
 	case *tgapi.AnswerCallback:
 		// ignore
 		return nil, nil
+	case *tgapi.InlineQuery, *tgapi.ChosenInlineResult:
+		// not a dialog, nowhere to answer
+		return nil, nil
+	case *tgapi.EditedMessage:
+		// too late to change answers
+		return nil, nil
+	case *tgapi.PollAnswer:
+		// vote in poll nobody waits for
+		return nil, nil
+	case *tgapi.CallbackQuery:
+		// stale button, no need to litter chat
+		answer(input, tgapi.AnswerCallback{Text: message, ShowAlert: true})
+		return nil, nil
 	}
-	if _, err := u.tgClient.SendMessage(ctx, u.Id, message); err != nil {
+	u.dropNoise(ctx)
+	if msgId, err := u.tgClient.SendMessage(ctx, u.recipient(), tgapi.PlainText(message)); err != nil {
 		return nil, xerrors.Errorf("send: %w", err)
+	} else {
+		u.noise = msgId
 	}
-	u.lastMessage = 0
 	return nil, nil
 }
 
@@ -56,18 +108,33 @@ func (u *User) doStart(ctx context.Context, input interface{}) (interface{}, err
 	}
 	u.stageNumber = 0
 	// menu
-	message := fmt.Sprintf("Hello, %s, whacha gonna do?", u.Name)
-	return u.ask(ctx, message, []tgapi.InlineKeyboardButton{
+	var message format.Builder
+	message.Plain("Hello, ").Bold(u.Name).Plain(", whacha gonna do?")
+	return u.ask(ctx, message.Text(), []tgapi.InlineKeyboardButton{
 		{Text: "Display achievements", CallbackData: listCallback},
 		{Text: "Add achievement", CallbackData: addCallback},
 	})
 }
 
+// doReferral remembers where user came from, e.g. campaign or shared template
+func (u *User) doReferral(ctx context.Context, input interface{}) (interface{}, error) {
+	msg, ok := input.(*tgapi.Message)
+	if !ok || u.Referral != "" {
+		return input, nil
+	}
+	if payload, ok := msg.StartParameter(); ok {
+		logging.S(ctx).Infof("User came by %q", payload)
+		u.Referral = payload
+	}
+	return input, nil
+}
+
 func (u *User) doTimer(ctx context.Context, input interface{}) (interface{}, error) {
 	rsp := input.(*timer.TimerEvent)
-	message := fmt.Sprintf("Time has come to report progress of %s", rsp.Name)
+	var message format.Builder
+	message.Plain("Time has come to report progress of ").Bold(rsp.Name)
 	u.currentName = rsp.Name
-	return u.ask(ctx, message, []tgapi.InlineKeyboardButton{
+	return u.ask(ctx, message.Text(), []tgapi.InlineKeyboardButton{
 		{Text: "Let's go", CallbackData: reportCallback},
 		{Text: "Later...", CallbackData: postponeCallback},
 	})
@@ -88,8 +155,8 @@ func (u *User) getNames() ([]string, int) {
 func (u *User) doList(ctx context.Context, input interface{}) (interface{}, error) {
 	names, index := u.getNames()
 	if len(names) == 0 {
-		message := fmt.Sprintf("Nothing to display")
-		if msgId, err := u.tgClient.EditInputKeyboard(ctx, u.Id, message, u.lastMessage,
+		message := tgapi.PlainText("Nothing to display")
+		if msgId, err := u.tgClient.EditInputKeyboard(ctx, u.recipient(), message, u.lastMessage,
 			tgapi.InlineKeyboard{InlineKeyboard: [][]tgapi.InlineKeyboardButton{
 				{{Text: "Back", CallbackData: stopListCallback}},
 			}}); err != nil {
@@ -97,9 +164,10 @@ func (u *User) doList(ctx context.Context, input interface{}) (interface{}, erro
 		} else {
 			u.lastMessage = msgId
 		}
+		u.dropNoise(ctx)
 		return nil, nil
 	}
-	message := fmt.Sprintf("What to display")
+	message := tgapi.PlainText("What to display")
 	keyboard := [][]tgapi.InlineKeyboardButton{}
 	for i := 0; i < listLength && i+index < len(names); i++ {
 		// TODO show progress as well
@@ -116,13 +184,14 @@ func (u *User) doList(ctx context.Context, input interface{}) (interface{}, erro
 	if index+listLength < len(names) {
 		controls = append(controls, tgapi.InlineKeyboardButton{Text: ">", CallbackData: forwardListCallback})
 	}
-	if msgId, err := u.tgClient.EditInputKeyboard(ctx, u.Id, message, u.lastMessage,
+	if msgId, err := u.tgClient.EditInputKeyboard(ctx, u.recipient(), message, u.lastMessage,
 		tgapi.InlineKeyboard{InlineKeyboard: append(keyboard, controls)},
 	); err != nil {
 		return nil, xerrors.Errorf("send: %w", err)
 	} else {
 		u.lastMessage = msgId
 	}
+	u.dropNoise(ctx)
 	return nil, nil
 }
 
@@ -146,11 +215,14 @@ func (u *User) doListBackward(ctx context.Context, input interface{}) (interface
 	return u.doList(ctx, input)
 }
 
-func (u *User) doDisplay(ctx context.Context, input interface{}) (interface{}, error) {
-	var message string
-	if limit, ok := u.Limits[u.currentName]; ok {
+// card describes achievement progress, ok is false if there is no such achievement
+func (u *User) card(name string) (text tgapi.Text, progress string, ok bool) {
+	var message format.Builder
+	if limit, found := u.Limits[name]; found {
+		message.Bold(limit.Name).Plain(".\n")
 		if limit.Done {
-			message = fmt.Sprintf("%s.\nAchivement DONE!\n%s", limit.Name, limit.Description)
+			progress = "DONE!"
+			message.Plain("Achivement ").Bold(progress)
 		} else {
 			var required, achieved int
 			if limit.Ascend {
@@ -160,18 +232,32 @@ func (u *User) doDisplay(ctx context.Context, input interface{}) (interface{}, e
 				required = limit.Initial - limit.Limit
 				achieved = limit.Initial - limit.Current
 			}
-			message = fmt.Sprintf("%s.\nAchivement progress: %.2f%% (%d/%d)\n%s",
-				limit.Name, (float32(achieved)/float32(required))*100, limit.Current, limit.Initial, limit.Description)
+			progress = fmt.Sprintf("%.2f%% (%d/%d)",
+				(float32(achieved)/float32(required))*100, limit.Current, limit.Initial)
+			message.Plain("Achivement progress: " + progress)
 		}
-	} else if strike, ok := u.Strikes[u.currentName]; ok {
+		message.Plain("\n").Italic(limit.Description)
+	} else if strike, found := u.Strikes[name]; found {
+		message.Bold(strike.Name).Plain(".\n")
 		if strike.Done {
-			message = fmt.Sprintf("%s.\nAchivement DONE!\n%s", strike.Name, strike.Description)
+			progress = "DONE!"
+			message.Plain("Achivement ").Bold(progress)
 		} else {
-			message = fmt.Sprintf("%s.\nAchivement progress: %.2f%% (%d/%d, best %d)\n%s",
-				strike.Name, (float32(strike.Last)/float32(strike.Strike))*100,
-				strike.Last, strike.Strike, strike.Best, strike.Description)
+			progress = fmt.Sprintf("%.2f%% (%d/%d, best %d)",
+				(float32(strike.Last)/float32(strike.Strike))*100,
+				strike.Last, strike.Strike, strike.Best)
+			message.Plain("Achivement progress: " + progress)
 		}
+		message.Plain("\n").Italic(strike.Description)
 	} else {
+		return tgapi.Text{}, "", false
+	}
+	return message.Text(), progress, true
+}
+
+func (u *User) doDisplay(ctx context.Context, input interface{}) (interface{}, error) {
+	message, _, ok := u.card(u.currentName)
+	if !ok {
 		return nil, xerrors.Errorf("unexpected achivement name: %s", u.currentName)
 	}
 	return u.ask(ctx, message, []tgapi.InlineKeyboardButton{
@@ -180,6 +266,40 @@ func (u *User) doDisplay(ctx context.Context, input interface{}) (interface{}, e
 	})
 }
 
+// doInline offers achievement cards matching the query to share
+func (u *User) doInline(ctx context.Context, input interface{}) (interface{}, error) {
+	query := input.(*tgapi.InlineQuery)
+	names, _ := u.getNames()
+	results := []tgapi.InlineQueryResult{}
+	for i, name := range names {
+		if !strings.Contains(strings.ToLower(name), strings.ToLower(query.Query)) {
+			continue
+		}
+		message, progress, ok := u.card(name)
+		if !ok {
+			continue
+		}
+		results = append(results, tgapi.InlineQueryResultArticle{
+			Id:                  strconv.Itoa(i),
+			Title:               name,
+			InputMessageContent: tgapi.MakeInputTextMessageContent(message),
+			Description:         progress,
+		})
+		if len(results) == inlineLength {
+			break
+		}
+	}
+	if err := u.tgClient.AnswerInlineQuery(ctx, tgapi.AnswerInlineQuery{
+		InlineQueryId: query.Id,
+		Results:       results,
+		CacheTime:     inlineCacheTime,
+		IsPersonal:    true,
+	}); err != nil {
+		return nil, xerrors.Errorf("answer: %w", err)
+	}
+	return nil, nil
+}
+
 func (u *User) doPostpone(ctx context.Context, input interface{}) (interface{}, error) {
 	// TODO custom postpone time via menu
 	// now postpone to 3 hour
@@ -190,12 +310,14 @@ func (u *User) doPostpone(ctx context.Context, input interface{}) (interface{},
 	} else {
 		return nil, xerrors.Errorf("unexpected achivement name: %s", u.currentName)
 	}
+	answer(input, tgapi.AnswerCallback{Text: "Postponed for 3 hours"})
 	return nil, nil
 }
 
 func (u *User) doStartAdd(ctx context.Context, input interface{}) (interface{}, error) {
-	message := fmt.Sprintf("Okay, now would you enter achievement name")
-	if _, err := u.tgClient.SendMessage(ctx, u.Id, message); err != nil {
+	message := addressed(input).Plain("Okay, now would you enter achievement name")
+	if _, err := u.tgClient.SendForceReply(ctx, u.recipient(), message.Text(),
+		tgapi.ForceReply{Placeholder: "Achievement name", Selective: true}); err != nil {
 		return nil, xerrors.Errorf("send: %w", err)
 	}
 	u.stageNumber = 0
@@ -211,31 +333,42 @@ func (u *User) dropAdd(ctx context.Context, input interface{}) (interface{}, err
 
 func (u *User) doAdd(ctx context.Context, input interface{}) (interface{}, error) {
 	rsp := input.(*tgapi.Message)
+	// Selective markup is shown to author of replied message only
+	to := u.recipient().Reply(rsp.MessageId)
 	switch u.stageNumber {
 	case 0:
 		u.newLimit.Name = rsp.Text
 		message := fmt.Sprintf("Now would you enter achievement description")
-		if _, err := u.tgClient.SendMessage(ctx, u.Id, message); err != nil {
+		if _, err := u.tgClient.SendForceReply(ctx, to, tgapi.PlainText(message),
+			tgapi.ForceReply{Placeholder: "Description", Selective: true}); err != nil {
 			return nil, xerrors.Errorf("send: %w", err)
 		}
 	case 1:
 		u.newLimit.Description = rsp.Text
 		message := fmt.Sprintf("Now what about limit to achieve?")
-		if _, err := u.tgClient.SendMessage(ctx, u.Id, message); err != nil {
+		if _, err := u.tgClient.CreateAnswerKeyboard(ctx, to, tgapi.PlainText(message),
+			numberKeyboard("10", "100", "1000")); err != nil {
 			return nil, xerrors.Errorf("send: %w", err)
 		}
 	case 2:
 		val, _ := strconv.Atoi(rsp.Text)
 		u.newLimit.Limit = val
 		message := fmt.Sprintf("Okay, and where are you now?")
-		if _, err := u.tgClient.SendMessage(ctx, u.Id, message); err != nil {
+		if _, err := u.tgClient.CreateAnswerKeyboard(ctx, to, tgapi.PlainText(message),
+			numberKeyboard("0")); err != nil {
 			return nil, xerrors.Errorf("send: %w", err)
 		}
 	case 3:
 		val, _ := strconv.Atoi(rsp.Text)
 		u.newLimit.Initial = val
-		message := fmt.Sprintf("So, you are to add %s, OK?", u.newLimit.Name)
-		return u.ask(ctx, message, []tgapi.InlineKeyboardButton{
+		var message format.Builder
+		message.Plain("So, you are to add ").Bold(u.newLimit.Name)
+		// quick answers are not needed anymore
+		if err := u.tgClient.DropKeyboard(ctx, u.recipient(), message.Text()); err != nil {
+			return nil, xerrors.Errorf("send: %w", err)
+		}
+		u.lastMessage = 0
+		return u.ask(ctx, tgapi.PlainText("OK?"), []tgapi.InlineKeyboardButton{
 			{Text: "OK", CallbackData: okCallback},
 			{Text: "Fix it", CallbackData: retryCallback},
 			{Text: "Fuck it", CallbackData: abortCallback},
@@ -245,6 +378,31 @@ func (u *User) doAdd(ctx context.Context, input interface{}) (interface{}, error
 	return nil, nil
 }
 
+// addressed starts message with mention of user who pressed the button in group,
+// so that Selective markup is shown to them only
+func addressed(input interface{}) *format.Builder {
+	var res format.Builder
+	if query, ok := input.(*tgapi.CallbackQuery); ok && !query.Origin().IsPrivate() {
+		res.Mention(query.From.FirstName, query.From).Plain(": ")
+	}
+	return &res
+}
+
+// numberKeyboard offers quick answers for numeric input
+func numberKeyboard(answers ...string) tgapi.AnswerKeyboard {
+	row := []tgapi.AnswerKeyboardButton{}
+	for _, answer := range answers {
+		row = append(row, tgapi.AnswerKeyboardButton{Text: answer})
+	}
+	return tgapi.AnswerKeyboard{
+		Keyboard:    [][]tgapi.AnswerKeyboardButton{row},
+		Resize:      true,
+		OneTime:     true,
+		Placeholder: "Enter a number",
+		Selective:   true,
+	}
+}
+
 func (u *User) doFinishAdd(ctx context.Context, input interface{}) (interface{}, error) {
 	u.newLimit.Ascend = u.newLimit.Limit > u.newLimit.Initial
 	u.newLimit.Current = u.newLimit.Initial
@@ -253,12 +411,14 @@ func (u *User) doFinishAdd(ctx context.Context, input interface{}) (interface{},
 	u.Limits[u.newLimit.Name] = u.newLimit
 	u.newLimit = nil
 	u.lastMessage = 0
+	answer(input, tgapi.AnswerCallback{Text: "Saved!"})
 	return nil, nil
 }
 
 func (u *User) doReport(ctx context.Context, input interface{}) (interface{}, error) {
-	message := fmt.Sprintf("Okay, now would you enter current state of %s", u.currentName)
-	if _, err := u.tgClient.SendMessage(ctx, u.Id, message); err != nil {
+	message := addressed(input).Plain("Okay, now would you enter current state of ").Bold(u.currentName)
+	if _, err := u.tgClient.SendForceReply(ctx, u.recipient(), message.Text(),
+		tgapi.ForceReply{Placeholder: "Enter a number", Selective: true}); err != nil {
 		return nil, xerrors.Errorf("send: %w", err)
 	}
 	return nil, nil
@@ -276,8 +436,9 @@ func (u *User) doFinishReport(ctx context.Context, input interface{}) (interface
 			limit.Done = true
 		}
 		if limit.Done {
-			message := fmt.Sprintf("Wow, you've done it! Gratz! Achievement %s completed!", u.currentName)
-			if _, err := u.tgClient.SendMessage(ctx, u.Id, message); err != nil {
+			var message format.Builder
+			message.Plain("Wow, you've done it! Gratz! Achievement ").Bold(u.currentName).Plain(" completed!")
+			if _, err := u.tgClient.SendMessage(ctx, u.recipient(), message.Text()); err != nil {
 				return nil, xerrors.Errorf("send: %w", err)
 			}
 		}
@@ -297,8 +458,9 @@ func (u *User) doFinishReport(ctx context.Context, input interface{}) (interface
 		}
 		if strike.Last >= strike.Strike {
 			strike.Done = true
-			message := fmt.Sprintf("Wow, you've done it! Gratz! Achievement %s completed!", u.currentName)
-			if _, err := u.tgClient.SendMessage(ctx, u.Id, message); err != nil {
+			var message format.Builder
+			message.Plain("Wow, you've done it! Gratz! Achievement ").Bold(u.currentName).Plain(" completed!")
+			if _, err := u.tgClient.SendMessage(ctx, u.recipient(), message.Text()); err != nil {
 				return nil, xerrors.Errorf("send: %w", err)
 			}
 		}
@@ -1,6 +1,7 @@
 package impl
 
 import (
+	"context"
 	"time"
 
 	"github.com/baldisbk/tgbot/pkg/statemachine"
@@ -8,9 +9,16 @@ import (
 	"github.com/baldisbk/tgbot/pkg/timer"
 )
 
+// ChatStates walks states kept in chat, see usercache
+type ChatStates interface {
+	// ForChat changes each state of chat with f and stores it
+	ForChat(ctx context.Context, chatId int64, f func(*User)) error
+}
+
 type userFactory struct {
 	tgClient tgapi.TGClient
 	timer    *timer.Timer
+	chats    ChatStates
 
 	config Config
 }
@@ -19,20 +27,26 @@ type Config struct {
 	DialogTimeout time.Duration `yaml:"dialog_timeout"`
 }
 
-func NewFactory(cfg Config, tgClient tgapi.TGClient, timer *timer.Timer) *userFactory {
-	return &userFactory{config: cfg, tgClient: tgClient, timer: timer}
+func NewFactory(cfg Config, tgClient tgapi.TGClient, timer *timer.Timer, chats ChatStates) *userFactory {
+	return &userFactory{config: cfg, tgClient: tgClient, timer: timer, chats: chats}
 }
 
-func (f *userFactory) MakeUser(u tgapi.User) *User {
+// MakeUser makes state of user in chat, private chat if none
+func (f *userFactory) MakeUser(chat tgapi.Chat, u tgapi.User) *User {
+	if chat.Id == 0 {
+		chat = tgapi.Chat{Id: int64(u.Id), Type: tgapi.PrivateChat, FirstName: u.FirstName}
+	}
 	res := &User{
 		Id:   u.Id,
 		Name: u.FirstName,
+		Chat: chat,
 
 		Limits:  map[string]*LimitAchievement{},
 		Strikes: map[string]*StrikeAchievement{},
 
 		tgClient: f.tgClient,
 		timer:    f.timer,
+		chats:    f.chats,
 
 		dialogTimeout: f.config.DialogTimeout,
 	}
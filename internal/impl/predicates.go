@@ -24,8 +24,15 @@ const (
 	okCallback    = "ok"
 	retryCallback = "retry"
 	abortCallback = "abort"
+
+	startCommand = tgapi.StartCommand
 )
 
+// Commands are registered on bot startup
+var Commands = []tgapi.BotCommand{
+	{Command: startCommand, Description: "Main menu"},
+}
+
 func callbackResponse(input interface{}) (bool, string) {
 	if input == nil {
 		return false, ""
@@ -47,8 +54,7 @@ func (u *User) isStart(ctx context.Context, state string, input interface{}) boo
 	if input == nil {
 		return false
 	}
-	rsp, ok := input.(*tgapi.Message)
-	return ok && rsp.Text == "/start"
+	return tgapi.IsCommandTo(u.tgClient.BotInfo().Username, startCommand)(ctx, state, input)
 }
 
 func (u *User) isTimer(ctx context.Context, state string, input interface{}) bool {
@@ -67,7 +73,7 @@ func (u *User) isRollback(ctx context.Context, state string, input interface{})
 	case *timer.TimerEvent:
 		return event.Type == timeoutTimer
 	case *tgapi.Message:
-		return event.Text == "/start"
+		return tgapi.IsCommandTo(u.tgClient.BotInfo().Username, startCommand)(ctx, state, input)
 	}
 	return false
 }
@@ -92,11 +98,22 @@ func (u *User) isDisplay(ctx context.Context, state string, input interface{}) b
 	return false
 }
 
+func isInline(ctx context.Context, state string, input interface{}) bool {
+	if input == nil {
+		return false
+	}
+	_, ok := input.(*tgapi.InlineQuery)
+	return ok
+}
+
 func (u *User) isValidInput(ctx context.Context, state string, input interface{}) bool {
 	if input == nil {
 		return false
 	}
-	rsp, ok := input.(*tgapi.Message)
+	if !tgapi.IsText(ctx, state, input) {
+		return false
+	}
+	rsp := input.(*tgapi.Message)
 	switch state {
 	case addState:
 		switch u.stageNumber {
@@ -116,5 +133,5 @@ func (u *User) isValidInput(ctx context.Context, state string, input interface{}
 			return false
 		}
 	}
-	return ok
+	return true
 }
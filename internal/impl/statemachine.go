@@ -17,6 +17,13 @@ func (u *User) DontUnderstand(state string) statemachine.Transition {
 	}
 }
 
+// Inline shares achievements from any state, dialog is not affected
+func (u *User) Inline(state string) statemachine.Transition {
+	return statemachine.Transition{
+		Source: state, Destination: state, Predicate: isInline, Callback: u.doInline,
+	}
+}
+
 func (u *User) timed(callbacks ...statemachine.SMCallback) statemachine.SMCallback {
 	cbs := append([]statemachine.SMCallback{u.doTimeout}, callbacks...)
 	return statemachine.CompositeCallback(cbs...)
@@ -29,7 +36,7 @@ func makeTransitions(res *User) []statemachine.Transition {
 			Source:      startState,
 			Destination: startState,
 			Predicate:   res.isStart,
-			Callback:    res.doStart,
+			Callback:    statemachine.CompositeCallback(res.doReferral, res.doStart),
 		},
 		{ // rollback - just do nothing
 			Source:      startState,
@@ -54,6 +61,7 @@ func makeTransitions(res *User) []statemachine.Transition {
 			Predicate:   checkCallback(addCallback),
 			Callback:    res.timed(res.doStartAdd),
 		},
+		res.Inline(startState),
 		res.DontUnderstand(startState),
 
 		// from timer
@@ -76,6 +84,7 @@ func makeTransitions(res *User) []statemachine.Transition {
 			// TODO custom postpone time via menu, two-step
 			Callback: statemachine.CompositeCallback(res.doPostpone, res.doStart),
 		},
+		res.Inline(timerState),
 		res.DontUnderstand(timerState),
 
 		// from list
@@ -109,6 +118,7 @@ func makeTransitions(res *User) []statemachine.Transition {
 			Predicate:   res.isDisplay,
 			Callback:    res.timed(res.doDisplay),
 		},
+		res.Inline(listState),
 		res.DontUnderstand(listState),
 
 		// from display
@@ -130,6 +140,7 @@ func makeTransitions(res *User) []statemachine.Transition {
 			Predicate:   checkCallback(stopListCallback),
 			Callback:    res.timed(res.doStart),
 		},
+		res.Inline(displayState),
 		res.DontUnderstand(displayState),
 
 		// from add
@@ -163,6 +174,7 @@ func makeTransitions(res *User) []statemachine.Transition {
 			Predicate:   res.isValidInput,
 			Callback:    res.timed(res.doAdd),
 		},
+		res.Inline(addState),
 		res.DontUnderstand(addState),
 
 		// from report
@@ -178,6 +190,7 @@ func makeTransitions(res *User) []statemachine.Transition {
 			Predicate:   res.isValidInput,
 			Callback:    statemachine.CompositeCallback(res.doFinishReport, res.doStart),
 		},
+		res.Inline(reportState),
 		res.DontUnderstand(reportState),
 	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/baldisbk/tgbot/pkg/logging"
 	"github.com/baldisbk/tgbot/pkg/statemachine"
 	"github.com/baldisbk/tgbot/pkg/tgapi"
 	"github.com/baldisbk/tgbot/pkg/timer"
@@ -17,8 +18,14 @@ const (
 type User struct {
 	Id      uint64
 	Name    string
+	Chat    tgapi.Chat // state belongs to, timers fire here
+	Thread  int64      // forum topic of Chat last used, timers fire there too
 	Limits  map[string]*LimitAchievement
 	Strikes map[string]*StrikeAchievement
+	// bot is blocked or removed from chat, no reminders
+	Inactive bool
+	// start payload of deep link user came with first
+	Referral string
 
 	// settings
 	dialogTimeout time.Duration
@@ -26,11 +33,14 @@ type User struct {
 	// internals
 	tgClient tgapi.TGClient
 	timer    *timer.Timer
+	chats    ChatStates
 	machine  statemachine.Machine
 
 	// dialog state
+	replyTo     tgapi.Recipient // chat of last input
 	currentName string
 	lastMessage uint64
+	noise       uint64 // "can't understand" reply, deleted on next one or menu
 	stageNumber int
 	newLimit    *LimitAchievement // add limit
 }
@@ -38,18 +48,89 @@ type User struct {
 // probably nothing needed
 func (u *User) UpdateState(context.Context, interface{}) error { return nil }
 func (u *User) Run(ctx context.Context, input interface{}) (interface{}, error) {
+	if event, ok := input.(*tgapi.ChatMemberUpdated); ok {
+		u.setMembership(ctx, event)
+		return nil, nil
+	}
+	u.setReplyTo(ctx, input)
 	return u.machine.Run(ctx, input)
 }
 
+// setReplyTo makes replies go to chat the input came from
+func (u *User) setReplyTo(ctx context.Context, input interface{}) {
+	var to tgapi.Recipient
+	switch event := input.(type) {
+	case *tgapi.Message:
+		to = event.Recipient()
+	case *tgapi.CallbackQuery:
+		to = event.Recipient()
+	case *timer.TimerEvent:
+		to = event.Recipient()
+	default:
+		return
+	}
+	if to != u.recipient() {
+		// can't edit message in other chat, left menu is stale
+		u.cleanup(ctx)
+		u.lastMessage = 0
+	}
+	u.replyTo = to
+	if to.ChatId == u.Chat.Id {
+		u.Thread = to.ThreadId
+	}
+}
+
+// setMembership stops reminders of all states in home chat when bot leaves it
+// and resumes them on return, event comes to state of one who did it only
+func (u *User) setMembership(ctx context.Context, event *tgapi.ChatMemberUpdated) {
+	if event.Chat.Id != u.Chat.Id || event.NewChatMember.User.Id != u.tgClient.BotInfo().Id {
+		// other chat or other member
+		return
+	}
+	present := event.NewChatMember.Present()
+	if present {
+		logging.S(ctx).Infof("Bot is %s, resume reminders", event.NewChatMember.Status)
+	} else {
+		logging.S(ctx).Infof("Bot is %s, stop reminders", event.NewChatMember.Status)
+		u.timer.CancelChat(u.Chat)
+	}
+	u.setPresent(present)
+	if err := u.chats.ForChat(ctx, u.Chat.Id, func(mate *User) { mate.setPresent(present) }); err != nil {
+		logging.S(ctx).Errorf("Update states of chat: %#v", err)
+	}
+}
+
+// setPresent marks state inactive or wakes it, timers are cancelled by caller
+func (u *User) setPresent(present bool) {
+	switch {
+	case !present && !u.Inactive:
+		u.Inactive = true
+		u.lastMessage = 0
+	case present && u.Inactive:
+		u.Inactive = false
+		u.Wake()
+	}
+}
+
+func (u *User) recipient() tgapi.Recipient {
+	if u.replyTo.ChatId != 0 {
+		return u.replyTo
+	}
+	return tgapi.Recipient{ChatId: u.Chat.Id, ThreadId: u.Thread}
+}
+
 func (u *User) SetTimer(name string, t time.Time) {
-	u.timer.SetAlarm(tgapi.User{Id: u.Id, FirstName: u.Name}, name, achievementTimer, t)
+	u.timer.SetAlarm(u.Chat, u.Thread, tgapi.User{Id: u.Id, FirstName: u.Name}, name, achievementTimer, t)
 }
 func (u *User) SetTimeout() {
-	u.timer.SetAlarm(tgapi.User{Id: u.Id, FirstName: u.Name},
+	u.timer.SetAlarm(u.Chat, u.Thread, tgapi.User{Id: u.Id, FirstName: u.Name},
 		"timeout", achievementTimer, time.Now().Add(u.dialogTimeout))
 }
 
 func (u *User) Wake() {
+	if u.Inactive {
+		return
+	}
 	for name, limit := range u.Limits {
 		u.SetTimer(name, limit.CheckTime)
 	}
@@ -0,0 +1,109 @@
+package impl
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/baldisbk/tgbot/pkg/engine"
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+	"github.com/baldisbk/tgbot/pkg/timer"
+)
+
+// chatStates is usercache with given states
+type chatStates []*User
+
+func (c chatStates) ForChat(ctx context.Context, chatId int64, f func(*User)) error {
+	for _, u := range c {
+		if u.Chat.Id == chatId {
+			f(u)
+		}
+	}
+	return nil
+}
+
+func TestSetMembership(t *testing.T) {
+	bot := tgapi.User{Id: 42}
+	group := tgapi.Chat{Id: -100, Type: tgapi.GroupChat}
+	other := tgapi.Chat{Id: -200, Type: tgapi.GroupChat}
+	testCases := []struct {
+		desc     string
+		chat     tgapi.Chat
+		member   tgapi.User
+		status   string
+		inactive bool
+		// states of group, then of other chat
+		expected []bool
+	}{
+		{
+			desc:     "removed",
+			chat:     group,
+			member:   bot,
+			status:   tgapi.MemberKicked,
+			expected: []bool{true, true, false},
+		},
+		{
+			desc:     "returned",
+			chat:     group,
+			member:   bot,
+			status:   tgapi.MemberMember,
+			inactive: true,
+			expected: []bool{false, false, true},
+		},
+		{
+			desc:     "other member",
+			chat:     group,
+			member:   tgapi.User{Id: 2},
+			status:   tgapi.MemberLeft,
+			expected: []bool{false, false, false},
+		},
+		{
+			desc:     "other chat",
+			chat:     other,
+			member:   bot,
+			status:   tgapi.MemberLeft,
+			expected: []bool{false, false, false},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert := require.New(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			tgClient := tgapi.NewMock()
+			tgClient.On("BotInfo").Return(tgapi.BotInfo{Id: bot.Id, IsBot: true}).Maybe()
+			tim := timer.NewTimer(ctx, timer.Config{Period: time.Hour}, engine.NewEngineMock())
+			defer tim.Shutdown()
+
+			var states chatStates
+			factory := NewFactory(Config{}, tgClient, tim, &states)
+			sender := factory.MakeUser(group, tgapi.User{Id: 1})
+			states = chatStates{
+				sender,
+				factory.MakeUser(group, tgapi.User{Id: 2}),
+				// state of other chat is untouched
+				factory.MakeUser(other, tgapi.User{Id: 1}),
+			}
+			for _, u := range states {
+				u.Inactive = tC.inactive
+			}
+
+			// event comes to sender state only
+			event := &tgapi.ChatMemberUpdated{
+				Chat:          tC.chat,
+				From:          tgapi.User{Id: 1},
+				NewChatMember: tgapi.ChatMember{User: tC.member, Status: tC.status},
+			}
+			_, err := sender.Run(ctx, event)
+			assert.NoError(err)
+			var inactive []bool
+			for _, u := range states {
+				inactive = append(inactive, u.Inactive)
+			}
+			assert.Equal(tC.expected, inactive)
+		})
+	}
+}
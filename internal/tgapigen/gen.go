@@ -0,0 +1,249 @@
+package tgapigen
+
+import (
+	"bytes"
+	"encoding/json"
+	"go/format"
+	"io"
+	"sort"
+	"strings"
+	"text/template"
+
+	"golang.org/x/xerrors"
+)
+
+// ======== Spec ========
+
+// Spec is subset of machine-readable Bot API description
+type Spec struct {
+	Version string            `json:"version"`
+	Types   map[string]Type   `json:"types"`
+	Methods map[string]Method `json:"methods"`
+}
+
+type Field struct {
+	Name        string   `json:"name"`
+	Types       []string `json:"types"`
+	Required    bool     `json:"required"`
+	Description string   `json:"description"`
+}
+
+type Type struct {
+	Name        string   `json:"name"`
+	Description []string `json:"description"`
+	Fields      []Field  `json:"fields"`
+}
+
+type Method struct {
+	Name        string   `json:"name"`
+	Description []string `json:"description"`
+	Returns     []string `json:"returns"`
+	Fields      []Field  `json:"fields"`
+}
+
+func Parse(r io.Reader) (Spec, error) {
+	var spec Spec
+	if err := json.NewDecoder(r).Decode(&spec); err != nil {
+		return Spec{}, xerrors.Errorf("decode: %w", err)
+	}
+	return spec, nil
+}
+
+// ======== Type mapping ========
+
+// hand-written types used instead of spec ones
+var aliases = map[string]string{
+	"InlineKeyboardMarkup": "InlineKeyboard",
+	"ReplyKeyboardMarkup":  "AnswerKeyboard",
+	"ReplyKeyboardRemove":  "DropKeyboard",
+	"KeyboardButton":       "AnswerKeyboardButton",
+}
+
+var scalars = map[string]string{
+	"String":  "string",
+	"Boolean": "bool",
+	"True":    "bool",
+	"Float":   "float64",
+}
+
+var initialisms = map[string]string{
+	"url":   "URL",
+	"vcard": "VCard",
+}
+
+// GoName makes CamelCase from snake_case or camelCase
+func GoName(name string) string {
+	var res strings.Builder
+	for _, part := range strings.Split(name, "_") {
+		if part == "" {
+			continue
+		}
+		if initialism, ok := initialisms[part]; ok {
+			res.WriteString(initialism)
+			continue
+		}
+		res.WriteString(strings.ToUpper(part[:1]) + part[1:])
+	}
+	return res.String()
+}
+
+// integer follows hand-written types: uint64 users and messages, int64 chats, threads and dates
+func integer(field string) string {
+	switch {
+	case strings.HasSuffix(field, "chat_id") || field == "message_thread_id" || strings.HasSuffix(field, "date"):
+		return "int64"
+	case field == "user_id" || strings.HasSuffix(field, "message_id") || strings.HasSuffix(field, "message_ids"):
+		return "uint64"
+	}
+	return "int"
+}
+
+// GoType maps spec types of field, objects are pointers if optional
+func GoType(field string, types []string, required bool) string {
+	switch {
+	case len(types) == 0:
+		return "interface{}"
+	case field == "reply_markup":
+		return "ReplyMarkup"
+	case len(types) == 2 && types[0] == "Integer" && types[1] == "String":
+		// chat id or @channelusername
+		return integer(field)
+	case len(types) > 1:
+		return "interface{}"
+	}
+	name := types[0]
+	if strings.HasPrefix(name, "Array of ") {
+		return "[]" + GoType(field, []string{strings.TrimPrefix(name, "Array of ")}, true)
+	}
+	if name == "Integer" {
+		return integer(field)
+	}
+	if scalar, ok := scalars[name]; ok {
+		return scalar
+	}
+	if alias, ok := aliases[name]; ok {
+		name = alias
+	}
+	if !required {
+		return "*" + name
+	}
+	return name
+}
+
+// ======== Generation ========
+
+type fieldData struct {
+	Name    string
+	Type    string
+	Tag     string
+	Comment string
+}
+
+type declData struct {
+	Name    string
+	Method  string
+	Comment []string
+	Fields  []fieldData
+	Returns string
+}
+
+func fields(spec []Field) []fieldData {
+	var res []fieldData
+	for _, f := range spec {
+		tag := f.Name
+		if !f.Required {
+			tag += ",omitempty"
+		}
+		res = append(res, fieldData{
+			Name:    GoName(f.Name),
+			Type:    GoType(f.Name, f.Types, f.Required),
+			Tag:     "`json:\"" + tag + "\"`",
+			Comment: f.Description,
+		})
+	}
+	return res
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch m := m.(type) {
+	case map[string]Type:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	case map[string]Method:
+		for k := range m {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var tmpl = template.Must(template.New("gen").Parse(`// Code generated by tgapigen from {{.Source}}; DO NOT EDIT.
+// {{.Version}}
+
+package {{.Package}}
+
+import "context"
+
+// ======== Types ========
+{{range .Types}}
+{{range .Comment}}// {{.}}
+{{end}}type {{.Name}} struct {
+{{range .Fields}}	{{.Name}} {{.Type}} {{.Tag}} // {{.Comment}}
+{{end}}}
+{{end}}
+// ======== Methods ========
+{{range .Methods}}
+const {{.Name}}Cmd = "{{.Method}}"
+
+type {{.Name}}Params struct {
+{{range .Fields}}	{{.Name}} {{.Type}} {{.Tag}} // {{.Comment}}
+{{end}}}
+
+{{range .Comment}}// {{.}}
+{{end}}func {{.Name}}(ctx context.Context, client Caller, params {{.Name}}Params) ({{.Returns}}, error) {
+	var res {{.Returns}}
+	err := client.Call(ctx, {{.Name}}Cmd, params, &res)
+	return res, err
+}
+{{end}}`))
+
+// Generate makes gofmt'ed Go source with types and methods of spec
+func Generate(spec Spec, pkg, source string) ([]byte, error) {
+	data := struct {
+		Package, Source, Version string
+		Types, Methods           []declData
+	}{Package: pkg, Source: source, Version: spec.Version}
+	for _, key := range sortedKeys(spec.Types) {
+		t := spec.Types[key]
+		data.Types = append(data.Types, declData{
+			Name:    GoName(t.Name),
+			Comment: t.Description,
+			Fields:  fields(t.Fields),
+		})
+	}
+	for _, key := range sortedKeys(spec.Methods) {
+		m := spec.Methods[key]
+		if len(m.Returns) != 1 {
+			return nil, xerrors.Errorf("method %s: want exactly one return type, got %v", m.Name, m.Returns)
+		}
+		data.Methods = append(data.Methods, declData{
+			Name:    GoName(m.Name),
+			Method:  m.Name,
+			Comment: m.Description,
+			Fields:  fields(m.Fields),
+			Returns: GoType("", m.Returns, true),
+		})
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, xerrors.Errorf("execute: %w", err)
+	}
+	src, err := format.Source(buf.Bytes())
+	if err != nil {
+		return nil, xerrors.Errorf("format: %w", err)
+	}
+	return src, nil
+}
@@ -0,0 +1,81 @@
+package tgapigen
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoName(t *testing.T) {
+	testCases := []struct {
+		name string
+		res  string
+	}{
+		{name: "chat_id", res: "ChatId"},
+		{name: "thumbnail_url", res: "ThumbnailURL"},
+		{name: "vcard", res: "VCard"},
+		{name: "sendDice", res: "SendDice"},
+		{name: "is_big", res: "IsBig"},
+	}
+	for _, c := range testCases {
+		t.Run(c.name, func(t *testing.T) {
+			require.Equal(t, c.res, GoName(c.name))
+		})
+	}
+}
+
+func TestGoType(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		field    string
+		types    []string
+		required bool
+		res      string
+	}{
+		{desc: "chat id", field: "chat_id", types: []string{"Integer", "String"}, required: true, res: "int64"},
+		{desc: "user id", field: "user_id", types: []string{"Integer"}, required: true, res: "uint64"},
+		{desc: "message id", field: "reply_to_message_id", types: []string{"Integer"}, res: "uint64"},
+		{desc: "message ids", field: "message_ids", types: []string{"Array of Integer"}, required: true, res: "[]uint64"},
+		{desc: "source chat", field: "from_chat_id", types: []string{"Integer", "String"}, required: true, res: "int64"},
+		{desc: "thread", field: "message_thread_id", types: []string{"Integer"}, res: "int64"},
+		{desc: "integer", field: "limit", types: []string{"Integer"}, res: "int"},
+		{desc: "float", field: "latitude", types: []string{"Float"}, required: true, res: "float64"},
+		{desc: "object", field: "location", types: []string{"Location"}, required: true, res: "Location"},
+		{desc: "optional object", field: "location", types: []string{"Location"}, res: "*Location"},
+		{desc: "alias", field: "keyboard", types: []string{"InlineKeyboardMarkup"}, required: true, res: "InlineKeyboard"},
+		{desc: "markup", field: "reply_markup", types: []string{"InlineKeyboardMarkup", "ForceReply"}, res: "ReplyMarkup"},
+		{desc: "array", field: "photos", types: []string{"Array of Array of PhotoSize"}, res: "[][]PhotoSize"},
+		{desc: "union", field: "media", types: []string{"InputFile", "String"}, required: true, res: "interface{}"},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			require.Equal(t, c.res, GoType(c.field, c.types, c.required))
+		})
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	assert := require.New(t)
+	spec, err := Parse(strings.NewReader(`{
+		"version": "test",
+		"types": {"Dice": {"name": "Dice", "description": ["dice"], "fields": [
+			{"name": "value", "types": ["Integer"], "required": true, "description": "value"}
+		]}},
+		"methods": {"sendDice": {"name": "sendDice", "description": ["roll"], "returns": ["Message"], "fields": [
+			{"name": "chat_id", "types": ["Integer", "String"], "required": true, "description": "chat"},
+			{"name": "emoji", "types": ["String"], "description": "emoji"}
+		]}}
+	}`))
+	assert.NoError(err)
+	src, err := Generate(spec, "tgapi", "spec.json")
+	assert.NoError(err)
+	assert.Contains(string(src), "type Dice struct {\n\tValue int `json:\"value\"` // value\n}")
+	assert.Contains(string(src), `const SendDiceCmd = "sendDice"`)
+	assert.Contains(string(src), "Emoji  string `json:\"emoji,omitempty\"` // emoji")
+	assert.Contains(string(src), "func SendDice(ctx context.Context, client Caller, params SendDiceParams) (Message, error)")
+
+	spec.Methods["sendDice"] = Method{Name: "sendDice"}
+	_, err = Generate(spec, "tgapi", "spec.json")
+	assert.Error(err)
+}
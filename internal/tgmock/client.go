@@ -15,7 +15,8 @@ type Client struct {
 }
 
 type ClientConfig struct {
-	Address string
+	Address     string
+	Middlewares []httputils.Middleware
 }
 
 func NewClient(cfg ClientConfig) (*Client, error) {
@@ -24,8 +25,9 @@ func NewClient(cfg ClientConfig) (*Client, error) {
 		return nil, xerrors.Errorf("parse address: %w", err)
 	}
 	return &Client{BaseClient: httputils.BaseClient{
-		Client: &http.Client{},
-		Path:   addr.String(),
+		Client:      &http.Client{},
+		Path:        addr.String(),
+		Middlewares: cfg.Middlewares,
 	}}, nil
 }
 
@@ -41,6 +43,21 @@ func (c *Client) SendMessage(ctx context.Context, userID uint64, message string)
 	return nil
 }
 
+// SendGroupMessage sends message from user in group, or in its forum topic if thread is set
+func (c *Client) SendGroupMessage(ctx context.Context, chatID int64, threadID int64, userID uint64, message string) error {
+	req := PrivateRequest{
+		UserID:   userID,
+		ChatID:   chatID,
+		ThreadID: threadID,
+		Payload:  message,
+	}
+	err := c.Request(ctx, http.MethodPut, privateMessagePath, req, nil)
+	if err != nil {
+		return xerrors.Errorf("request: %w", err)
+	}
+	return nil
+}
+
 func (c *Client) PushButton(ctx context.Context, userID uint64, data string) error {
 	req := PrivateRequest{
 		UserID:  userID,
@@ -53,6 +70,73 @@ func (c *Client) PushButton(ctx context.Context, userID uint64, data string) err
 	return nil
 }
 
+func (c *Client) SendDocument(ctx context.Context, userID uint64, name string, contents []byte, caption string) error {
+	req := PrivateRequest{
+		UserID:   userID,
+		Payload:  caption,
+		FileName: name,
+		Contents: contents,
+	}
+	err := c.Request(ctx, http.MethodPut, privateDocumentPath, req, nil)
+	if err != nil {
+		return xerrors.Errorf("request: %w", err)
+	}
+	return nil
+}
+
+// SendInlineQuery types "@bot query" in some chat
+func (c *Client) SendInlineQuery(ctx context.Context, userID uint64, query string) error {
+	req := PrivateRequest{
+		UserID:  userID,
+		Payload: query,
+	}
+	err := c.Request(ctx, http.MethodPut, privateInlinePath, req, nil)
+	if err != nil {
+		return xerrors.Errorf("request: %w", err)
+	}
+	return nil
+}
+
+// ChooseInlineResult picks inline result with given id
+func (c *Client) ChooseInlineResult(ctx context.Context, userID uint64, resultID string) error {
+	req := PrivateRequest{
+		UserID:  userID,
+		Payload: resultID,
+	}
+	err := c.Request(ctx, http.MethodPut, privateChosenPath, req, nil)
+	if err != nil {
+		return xerrors.Errorf("request: %w", err)
+	}
+	return nil
+}
+
+// SetBotStatus blocks (kicked) or unblocks (member) bot by user
+func (c *Client) SetBotStatus(ctx context.Context, userID uint64, status string) error {
+	req := PrivateRequest{
+		UserID:  userID,
+		Payload: status,
+	}
+	err := c.Request(ctx, http.MethodPut, privateMemberPath, req, nil)
+	if err != nil {
+		return xerrors.Errorf("request: %w", err)
+	}
+	return nil
+}
+
+// Vote votes in poll, no options to retract vote
+func (c *Client) Vote(ctx context.Context, userID uint64, pollID string, options ...int) error {
+	req := PrivateRequest{
+		UserID:  userID,
+		Payload: pollID,
+		Options: options,
+	}
+	err := c.Request(ctx, http.MethodPut, privateVotePath, req, nil)
+	if err != nil {
+		return xerrors.Errorf("request: %w", err)
+	}
+	return nil
+}
+
 func (c *Client) GetHistory(ctx context.Context, userID uint64) ([]tgapi.Update, error) {
 	req := PrivateRequest{
 		UserID: userID,
@@ -0,0 +1,58 @@
+package tgmock
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/baldisbk/tgbot/pkg/logging"
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
+
+func commandsKey(scope *tgapi.BotCommandScope, language string) string {
+	if scope == nil {
+		scope = &tgapi.BotCommandScope{Type: tgapi.DefaultScope}
+	}
+	b, _ := json.Marshal(scope)
+	return string(b) + "/" + language
+}
+
+func (s *Server) setCommands(rw http.ResponseWriter, r *http.Request) {
+	var payload tgapi.SetMyCommands
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	logging.S(r.Context()).Infof("--- set-commands %v", payload)
+	s.mx.Lock()
+	s.commands[commandsKey(payload.Scope, payload.LanguageCode)] = payload.Commands
+	s.mx.Unlock()
+	rw.Write([]byte(`{"ok":true,"result":true}`))
+}
+
+func (s *Server) getCommands(rw http.ResponseWriter, r *http.Request) {
+	var payload tgapi.MyCommands
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	s.mx.Lock()
+	commands := s.commands[commandsKey(payload.Scope, payload.LanguageCode)]
+	s.mx.Unlock()
+	if commands == nil {
+		commands = []tgapi.BotCommand{}
+	}
+	s.writeResult(rw, r, okResponse{Result: commands, Ok: true})
+}
+
+func (s *Server) deleteCommands(rw http.ResponseWriter, r *http.Request) {
+	var payload tgapi.MyCommands
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	logging.S(r.Context()).Infof("--- delete-commands %v", payload)
+	s.mx.Lock()
+	delete(s.commands, commandsKey(payload.Scope, payload.LanguageCode))
+	s.mx.Unlock()
+	rw.Write([]byte(`{"ok":true,"result":true}`))
+}
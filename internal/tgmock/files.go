@@ -0,0 +1,137 @@
+package tgmock
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+
+	"github.com/baldisbk/tgbot/pkg/logging"
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
+
+const maxUploadMemory = 32 << 20
+
+type mockFile struct {
+	Name     string
+	Contents []byte
+}
+
+func (s *Server) storeFile(name string, contents []byte) tgapi.File {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	id := uuid.NewString()
+	s.files[id] = mockFile{Name: name, Contents: contents}
+	return tgapi.File{
+		FileId:       id,
+		FileUniqueId: id,
+		FileSize:     int64(len(contents)),
+		FilePath:     "documents/" + id,
+	}
+}
+
+func (s *Server) media(rw http.ResponseWriter, r *http.Request) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		// file_id, same as messages
+		s.message(rw, r)
+		return
+	}
+	if err := r.ParseMultipartForm(maxUploadMemory); err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	msg := tgapi.Message{MessageId: s.nextMessageId()}
+	for name, headers := range r.MultipartForm.File {
+		for _, header := range headers {
+			logging.S(r.Context()).Infof("< bot < [%s %s, %d bytes] %v",
+				name, header.Filename, header.Size, r.MultipartForm.Value)
+			file, err := header.Open()
+			if err != nil {
+				s.writeError(rw, r, http.StatusInternalServerError, "serve err: %s", err)
+				return
+			}
+			contents, err := io.ReadAll(file)
+			file.Close()
+			if err != nil {
+				s.writeError(rw, r, http.StatusInternalServerError, "serve err: %s", err)
+				return
+			}
+			stored := s.storeFile(header.Filename, contents)
+			switch name {
+			case "photo":
+				msg.Photo = []tgapi.PhotoSize{{FileId: stored.FileId, FileUniqueId: stored.FileUniqueId, FileSize: stored.FileSize}}
+			case "audio":
+				msg.Audio = &tgapi.Audio{FileId: stored.FileId, FileUniqueId: stored.FileUniqueId,
+					FileName: header.Filename, FileSize: stored.FileSize}
+			case "voice":
+				msg.Voice = &tgapi.Voice{FileId: stored.FileId, FileUniqueId: stored.FileUniqueId, FileSize: stored.FileSize}
+			default:
+				msg.Document = &tgapi.Document{FileId: stored.FileId, FileUniqueId: stored.FileUniqueId,
+					FileName: header.Filename, FileSize: stored.FileSize}
+			}
+		}
+	}
+	s.writeResult(rw, r, okResponse{Result: msg, Ok: true})
+}
+
+func (s *Server) getFile(rw http.ResponseWriter, r *http.Request) {
+	var payload tgapi.GetFile
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	s.mx.Lock()
+	file, ok := s.files[payload.FileId]
+	s.mx.Unlock()
+	if !ok {
+		rw.WriteHeader(http.StatusBadRequest)
+		s.writeResult(rw, r, tgapi.ErrorResponse{
+			ErrorCode:   http.StatusBadRequest,
+			Description: "Bad Request: invalid file_id",
+		})
+		return
+	}
+	logging.S(r.Context()).Infof("--- get-file %s (%s)", payload.FileId, file.Name)
+	s.writeResult(rw, r, okResponse{
+		Result: tgapi.File{
+			FileId:       payload.FileId,
+			FileUniqueId: payload.FileId,
+			FileSize:     int64(len(file.Contents)),
+			FilePath:     "documents/" + payload.FileId,
+		},
+		Ok: true,
+	})
+}
+
+func (s *Server) download(rw http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(mux.Vars(r)["path"], "documents/")
+	s.mx.Lock()
+	file, ok := s.files[id]
+	s.mx.Unlock()
+	if !ok {
+		rw.WriteHeader(http.StatusNotFound)
+		return
+	}
+	logging.S(r.Context()).Infof("--- download %s (%s)", id, file.Name)
+	rw.Write(file.Contents)
+}
+
+// okResponse is envelope of successful result
+type okResponse struct {
+	Ok     bool        `json:"ok"`
+	Result interface{} `json:"result"`
+}
+
+func (s *Server) writeResult(rw http.ResponseWriter, r *http.Request, result interface{}) {
+	b, err := json.Marshal(result)
+	if err != nil {
+		s.writeError(rw, r, http.StatusInternalServerError, "marshal err: %s", err)
+		return
+	}
+	if _, err := rw.Write(b); err != nil {
+		logging.S(r.Context()).Errorf("write err: %s", err)
+	}
+}
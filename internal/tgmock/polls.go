@@ -0,0 +1,120 @@
+package tgmock
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"golang.org/x/xerrors"
+
+	"github.com/baldisbk/tgbot/pkg/logging"
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
+
+var noPollError = xerrors.New("no such open poll")
+
+func (s *Server) sendPoll(rw http.ResponseWriter, r *http.Request) {
+	var payload tgapi.SendPoll
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	logging.S(r.Context()).Infof("< bot < poll %q %v", payload.Question, payload.Options)
+	poll := &tgapi.Poll{
+		Id:                    uuid.NewString(),
+		Question:              payload.Question,
+		IsAnonymous:           payload.IsAnonymous,
+		Type:                  payload.Type,
+		AllowsMultipleAnswers: payload.AllowsMultipleAnswers,
+		CorrectOptionId:       payload.CorrectOptionId,
+		Explanation:           payload.Explanation,
+	}
+	for _, option := range payload.Options {
+		poll.Options = append(poll.Options, tgapi.PollOption{Text: option.Text})
+	}
+	s.mx.Lock()
+	s.lastMessageId++
+	msgId := s.lastMessageId
+	s.polls[poll.Id] = poll
+	s.pollMessages[msgId] = poll.Id
+	msg := tgapi.Message{MessageId: msgId, Chat: tgapi.Chat{Id: payload.ChatId}, Poll: copyPoll(poll)}
+	s.mx.Unlock()
+	s.writeResult(rw, r, okResponse{Result: msg, Ok: true})
+}
+
+func (s *Server) stopPoll(rw http.ResponseWriter, r *http.Request) {
+	var payload tgapi.StopPoll
+	if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	s.mx.Lock()
+	poll, ok := s.polls[s.pollMessages[payload.MessageId]]
+	if ok {
+		poll.IsClosed = true
+		poll = copyPoll(poll)
+	}
+	s.mx.Unlock()
+	if !ok {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", noPollError)
+		return
+	}
+	logging.S(r.Context()).Infof("< bot < stop poll %s", poll.Id)
+	s.push(func(id uint64) tgapi.Update { return tgapi.Update{Poll: poll} })
+	s.writeResult(rw, r, okResponse{Result: *poll, Ok: true})
+}
+
+// privateVote votes in poll, payload is poll id
+func (s *Server) privateVote(rw http.ResponseWriter, r *http.Request) {
+	cts, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(rw, r, http.StatusInternalServerError, "serve err: %s", err)
+		return
+	}
+	var payload PrivateRequest
+	err = json.Unmarshal(cts, &payload)
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	s.mx.Lock()
+	poll, ok := s.polls[payload.Payload]
+	if ok && !poll.IsClosed {
+		for _, option := range payload.Options {
+			if option >= 0 && option < len(poll.Options) {
+				poll.Options[option].VoterCount++
+			}
+		}
+		if len(payload.Options) != 0 {
+			poll.TotalVoterCount++
+		}
+		poll = copyPoll(poll)
+	}
+	s.mx.Unlock()
+	if !ok || poll.IsClosed {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", noPollError)
+		return
+	}
+	logging.S(r.Context()).Infof("> usr %d > vote %s %v", payload.UserID, payload.Payload, payload.Options)
+	s.push(func(id uint64) tgapi.Update { return tgapi.Update{Poll: poll} })
+	if !poll.IsAnonymous {
+		s.push(func(id uint64) tgapi.Update {
+			return tgapi.Update{
+				PollAnswer: &tgapi.PollAnswer{
+					PollId:    payload.Payload,
+					Voter:     tgapi.User{Id: payload.UserID, FirstName: "Test user"},
+					OptionIds: payload.Options,
+				},
+			}
+		})
+	}
+	rw.WriteHeader(http.StatusOK)
+}
+
+// must be called under lock, stored poll changes on votes
+func copyPoll(poll *tgapi.Poll) *tgapi.Poll {
+	res := *poll
+	res.Options = append([]tgapi.PollOption{}, poll.Options...)
+	return &res
+}
@@ -2,6 +2,7 @@ package tgmock
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"net/http"
 
@@ -12,6 +13,35 @@ import (
 type PrivateRequest struct {
 	UserID  uint64 `json:"user_id"`
 	Payload string `json:"payload,omitempty"`
+	// group chat and forum topic, private chat with user if empty
+	ChatID   int64 `json:"chat_id,omitempty"`
+	ThreadID int64 `json:"thread_id,omitempty"`
+	// document upload
+	FileName string `json:"file_name,omitempty"`
+	Contents []byte `json:"contents,omitempty"`
+	// poll vote
+	Options []int `json:"options,omitempty"`
+}
+
+func (r PrivateRequest) chat() tgapi.Chat {
+	if r.ChatID == 0 {
+		return tgapi.Chat{Id: int64(r.UserID), Type: tgapi.PrivateChat, FirstName: "Test user"}
+	}
+	chat := tgapi.Chat{Id: r.ChatID, Type: tgapi.SupergroupChat, Title: "Test group"}
+	if r.ThreadID != 0 {
+		chat.IsForum = true
+	}
+	return chat
+}
+
+func (r PrivateRequest) message(id uint64) *tgapi.Message {
+	return &tgapi.Message{
+		MessageId:       id,
+		From:            tgapi.User{Id: r.UserID, FirstName: "Test user"},
+		Chat:            r.chat(),
+		MessageThreadId: r.ThreadID,
+		IsTopicMessage:  r.ThreadID != 0,
+	}
 }
 
 func (s *Server) privateMessage(rw http.ResponseWriter, r *http.Request) {
@@ -27,18 +57,10 @@ func (s *Server) privateMessage(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	logging.S(r.Context()).Infof("> usr %d > : %s", payload.UserID, payload.Payload)
-	var id uint64
-	if len(s.messages) != 0 {
-		id = s.messages[len(s.messages)-1].UpdateId + 1
-	}
-	s.messages = append(s.messages, tgapi.Update{
-		UpdateId: id,
-		Message: &tgapi.Message{
-			MessageId: id,
-			From:      tgapi.User{Id: payload.UserID, FirstName: "Test user"},
-			Chat:      tgapi.Chat{},
-			Text:      payload.Payload,
-		},
+	s.push(func(id uint64) tgapi.Update {
+		msg := payload.message(id)
+		msg.Text = payload.Payload
+		return tgapi.Update{Message: msg}
 	})
 	rw.WriteHeader(http.StatusOK)
 	return
@@ -57,16 +79,14 @@ func (s *Server) privateButton(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	logging.S(r.Context()).Infof("> usr %d > + %s", payload.UserID, payload.Payload)
-	var id uint64
-	if len(s.messages) != 0 {
-		id = s.messages[len(s.messages)-1].UpdateId + 1
-	}
-	s.messages = append(s.messages, tgapi.Update{
-		UpdateId: id,
-		CallbackQuery: &tgapi.CallbackQuery{
-			From: tgapi.User{Id: payload.UserID, FirstName: "Test user"},
-			Data: payload.Payload,
-		},
+	s.push(func(id uint64) tgapi.Update {
+		return tgapi.Update{
+			CallbackQuery: &tgapi.CallbackQuery{
+				From:   tgapi.User{Id: payload.UserID, FirstName: "Test user"},
+				Source: payload.message(id),
+				Data:   payload.Payload,
+			},
+		}
 	})
 	rw.WriteHeader(http.StatusOK)
 	return
@@ -85,17 +105,121 @@ func (s *Server) privateHistory(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	logging.S(r.Context()).Infof("==== HISTORY %d", payload.UserID)
-	var id uint64
-	if len(s.messages) != 0 {
-		id = s.messages[len(s.messages)-1].UpdateId + 1
-	}
-	s.messages = append(s.messages, tgapi.Update{
-		UpdateId: id,
-		CallbackQuery: &tgapi.CallbackQuery{
-			From: tgapi.User{FirstName: "Test user"},
-			Data: string(cts),
-		},
+	s.push(func(id uint64) tgapi.Update {
+		return tgapi.Update{
+			CallbackQuery: &tgapi.CallbackQuery{
+				From: tgapi.User{FirstName: "Test user"},
+				Data: string(cts),
+			},
+		}
 	})
 	rw.WriteHeader(http.StatusOK)
 	return
 }
+
+func (s *Server) privateDocument(rw http.ResponseWriter, r *http.Request) {
+	cts, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(rw, r, http.StatusInternalServerError, "serve err: %s", err)
+		return
+	}
+	var payload PrivateRequest
+	err = json.Unmarshal(cts, &payload)
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	logging.S(r.Context()).Infof("> usr %d > [%s, %d bytes] %s",
+		payload.UserID, payload.FileName, len(payload.Contents), payload.Payload)
+	file := s.storeFile(payload.FileName, payload.Contents)
+	s.push(func(id uint64) tgapi.Update {
+		msg := payload.message(id)
+		msg.Document = &tgapi.Document{
+			FileId:       file.FileId,
+			FileUniqueId: file.FileUniqueId,
+			FileName:     payload.FileName,
+			FileSize:     file.FileSize,
+		}
+		msg.Caption = payload.Payload
+		return tgapi.Update{Message: msg}
+	})
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) privateInline(rw http.ResponseWriter, r *http.Request) {
+	cts, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(rw, r, http.StatusInternalServerError, "serve err: %s", err)
+		return
+	}
+	var payload PrivateRequest
+	err = json.Unmarshal(cts, &payload)
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	logging.S(r.Context()).Infof("> usr %d > @%s %s", payload.UserID, mockBotUsername, payload.Payload)
+	s.push(func(id uint64) tgapi.Update {
+		return tgapi.Update{
+			InlineQuery: &tgapi.InlineQuery{
+				Id:       fmt.Sprintf("%d", id),
+				From:     tgapi.User{Id: payload.UserID, FirstName: "Test user"},
+				Query:    payload.Payload,
+				ChatType: payload.chat().Type,
+			},
+		}
+	})
+	rw.WriteHeader(http.StatusOK)
+}
+
+func (s *Server) privateChosen(rw http.ResponseWriter, r *http.Request) {
+	cts, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(rw, r, http.StatusInternalServerError, "serve err: %s", err)
+		return
+	}
+	var payload PrivateRequest
+	err = json.Unmarshal(cts, &payload)
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	logging.S(r.Context()).Infof("> usr %d > @%s * %s", payload.UserID, mockBotUsername, payload.Payload)
+	s.push(func(id uint64) tgapi.Update {
+		return tgapi.Update{
+			ChosenInlineResult: &tgapi.ChosenInlineResult{
+				ResultId: payload.Payload,
+				From:     tgapi.User{Id: payload.UserID, FirstName: "Test user"},
+			},
+		}
+	})
+	rw.WriteHeader(http.StatusOK)
+}
+
+// privateMember changes bot status in chat, payload is new status
+func (s *Server) privateMember(rw http.ResponseWriter, r *http.Request) {
+	cts, err := io.ReadAll(r.Body)
+	if err != nil {
+		s.writeError(rw, r, http.StatusInternalServerError, "serve err: %s", err)
+		return
+	}
+	var payload PrivateRequest
+	err = json.Unmarshal(cts, &payload)
+	if err != nil {
+		s.writeError(rw, r, http.StatusBadRequest, "bad request: %s", err)
+		return
+	}
+	logging.S(r.Context()).Infof("> usr %d > bot is %s", payload.UserID, payload.Payload)
+	bot := tgapi.User{Id: mockBotId, FirstName: "Mock bot"}
+	s.push(func(id uint64) tgapi.Update {
+		return tgapi.Update{
+			MyChatMember: &tgapi.ChatMemberUpdated{
+				Chat:          payload.chat(),
+				From:          tgapi.User{Id: payload.UserID, FirstName: "Test user"},
+				OldChatMember: tgapi.ChatMember{User: bot, Status: tgapi.MemberMember},
+				NewChatMember: tgapi.ChatMember{User: bot, Status: payload.Payload},
+			},
+		}
+	})
+	rw.WriteHeader(http.StatusOK)
+}
@@ -7,6 +7,9 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"path"
+	"sync"
+	"time"
 
 	"github.com/baldisbk/tgbot/internal/config"
 	"github.com/baldisbk/tgbot/pkg/logging"
@@ -15,9 +18,19 @@ import (
 )
 
 const (
-	privateMessagePath = "/private/message"
-	privateButtonPath  = "/private/button"
-	privateHistoryPath = "/private/history"
+	mockBotId       = 1
+	mockBotUsername = "MockBot"
+)
+
+const (
+	privateMessagePath  = "/private/message"
+	privateButtonPath   = "/private/button"
+	privateHistoryPath  = "/private/history"
+	privateDocumentPath = "/private/document"
+	privateInlinePath   = "/private/inline"
+	privateChosenPath   = "/private/chosen"
+	privateMemberPath   = "/private/member"
+	privateVotePath     = "/private/vote"
 )
 
 type HistoryEntry struct {
@@ -26,7 +39,18 @@ type HistoryEntry struct {
 }
 
 type Server struct {
+	mx       sync.Mutex
 	messages []tgapi.Update
+	// closed on each new message to wake long polls
+	pushed chan struct{}
+	// uploaded by bot or users, by file_id
+	files map[string]mockFile
+	// registered by bot, by scope and language
+	commands map[string][]tgapi.BotCommand
+	// sent by bot, by poll id and by message id
+	polls         map[string]*tgapi.Poll
+	pollMessages  map[uint64]string
+	lastMessageId uint64
 }
 
 type Config struct {
@@ -36,7 +60,13 @@ type Config struct {
 }
 
 func NewServer(ctx context.Context, cfg Config) *http.Server {
-	srv := Server{}
+	srv := Server{
+		pushed:       make(chan struct{}),
+		files:        map[string]mockFile{},
+		commands:     map[string][]tgapi.BotCommand{},
+		polls:        map[string]*tgapi.Poll{},
+		pollMessages: map[uint64]string{},
+	}
 
 	mx := mux.NewRouter()
 	mx.HandleFunc("/{token}/"+tgapi.TestCmd, srv.ping)
@@ -44,10 +74,37 @@ func NewServer(ctx context.Context, cfg Config) *http.Server {
 	mx.HandleFunc("/{token}/"+tgapi.SendCmd, srv.message)
 	mx.HandleFunc("/{token}/"+tgapi.AnswerCmd, srv.callback)
 	mx.HandleFunc("/{token}/"+tgapi.EditCmd, srv.message)
+	mx.HandleFunc("/{token}/"+tgapi.WebhookCmd, srv.webhook)
+	mx.HandleFunc("/{token}/"+tgapi.DeleteWebhookCmd, srv.manage)
+	mx.HandleFunc("/{token}/"+tgapi.PhotoCmd, srv.media)
+	mx.HandleFunc("/{token}/"+tgapi.DocumentCmd, srv.media)
+	mx.HandleFunc("/{token}/"+tgapi.AudioCmd, srv.media)
+	mx.HandleFunc("/{token}/"+tgapi.VoiceCmd, srv.media)
+	mx.HandleFunc("/{token}/"+tgapi.GetFileCmd, srv.getFile)
+	mx.HandleFunc("/file/{token}/{path:.*}", srv.download)
+	mx.HandleFunc("/{token}/"+tgapi.SetCommandsCmd, srv.setCommands)
+	mx.HandleFunc("/{token}/"+tgapi.GetCommandsCmd, srv.getCommands)
+	mx.HandleFunc("/{token}/"+tgapi.DeleteCommandsCmd, srv.deleteCommands)
+	mx.HandleFunc("/{token}/"+tgapi.InlineAnswerCmd, srv.inlineAnswer)
+	mx.HandleFunc("/{token}/"+tgapi.DeleteMessageCmd, srv.manage)
+	mx.HandleFunc("/{token}/"+tgapi.DeleteMessagesCmd, srv.manage)
+	mx.HandleFunc("/{token}/"+tgapi.PinChatMessageCmd, srv.manage)
+	mx.HandleFunc("/{token}/"+tgapi.UnpinChatMessageCmd, srv.manage)
+	mx.HandleFunc("/{token}/"+tgapi.EditMessageReplyMarkupCmd, srv.message)
+	mx.HandleFunc("/{token}/"+tgapi.CopyMessageCmd, srv.message)
+	mx.HandleFunc("/{token}/"+tgapi.ForwardMessageCmd, srv.message)
+	mx.HandleFunc("/{token}/"+tgapi.SendChatActionCmd, srv.manage)
+	mx.HandleFunc("/{token}/"+tgapi.PollCmd, srv.sendPoll)
+	mx.HandleFunc("/{token}/"+tgapi.StopPollCmd, srv.stopPoll)
 
 	mx.HandleFunc(privateMessagePath, srv.privateMessage)
 	mx.HandleFunc(privateButtonPath, srv.privateButton)
 	mx.HandleFunc(privateHistoryPath, srv.privateHistory)
+	mx.HandleFunc(privateDocumentPath, srv.privateDocument)
+	mx.HandleFunc(privateInlinePath, srv.privateInline)
+	mx.HandleFunc(privateChosenPath, srv.privateChosen)
+	mx.HandleFunc(privateMemberPath, srv.privateMember)
+	mx.HandleFunc(privateVotePath, srv.privateVote)
 
 	mx.NotFoundHandler = http.HandlerFunc(srv.dflt)
 
@@ -98,8 +155,16 @@ func (s *Server) ping(rw http.ResponseWriter, r *http.Request) {
 	}
 	vars := mux.Vars(r)
 	logging.S(r.Context()).Infof("ping %s", vars["token"])
-	rw.Write([]byte("{}"))
-	return
+	s.writeResult(rw, r, okResponse{
+		Result: tgapi.BotInfo{
+			Id:            mockBotId,
+			IsBot:         true,
+			FirstName:     "Mock bot",
+			Username:      mockBotUsername,
+			CanJoinGroups: true,
+		},
+		Ok: true,
+	})
 }
 
 func (s *Server) update(rw http.ResponseWriter, r *http.Request) {
@@ -115,13 +180,17 @@ func (s *Server) update(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	logging.S(r.Context()).Infof("--- get-update %s", string(cts))
-	var messages []tgapi.Update
-	for _, up := range s.messages {
-		if up.UpdateId >= payload.Offset {
-			messages = append(messages, up)
+	messages, pushed := s.poll(payload.Offset)
+	if len(messages) == 0 && payload.Timeout > 0 {
+		select {
+		case <-pushed:
+			messages, _ = s.poll(payload.Offset)
+		case <-time.After(time.Duration(payload.Timeout) * time.Second):
+		case <-r.Context().Done():
+			return
 		}
 	}
-	b, err := json.Marshal(tgapi.UpdateResponse{Result: messages, Ok: true})
+	b, err := json.Marshal(okResponse{Result: messages, Ok: true})
 	if err != nil {
 		s.writeError(rw, r, http.StatusInternalServerError, "marshal err: %s", err)
 		return
@@ -132,6 +201,32 @@ func (s *Server) update(rw http.ResponseWriter, r *http.Request) {
 	}
 }
 
+func (s *Server) poll(offset uint64) ([]tgapi.Update, <-chan struct{}) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	var messages []tgapi.Update
+	for _, up := range s.messages {
+		if up.UpdateId >= offset {
+			messages = append(messages, up)
+		}
+	}
+	return messages, s.pushed
+}
+
+func (s *Server) push(makeUpdate func(id uint64) tgapi.Update) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	var id uint64
+	if len(s.messages) != 0 {
+		id = s.messages[len(s.messages)-1].UpdateId + 1
+	}
+	upd := makeUpdate(id)
+	upd.UpdateId = id
+	s.messages = append(s.messages, upd)
+	close(s.pushed)
+	s.pushed = make(chan struct{})
+}
+
 func (s *Server) message(rw http.ResponseWriter, r *http.Request) {
 	cts, err := io.ReadAll(r.Body)
 	if err != nil {
@@ -139,7 +234,39 @@ func (s *Server) message(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	logging.S(r.Context()).Infof("< bot < : %s", string(cts))
-	rw.Write([]byte("{}"))
+	var payload struct {
+		ChatId    int64  `json:"chat_id"`
+		MessageId uint64 `json:"message_id"`
+		Text      string `json:"text"`
+	}
+	// form requests are logged only
+	json.Unmarshal(cts, &payload)
+	msg := tgapi.Message{MessageId: payload.MessageId, Chat: tgapi.Chat{Id: payload.ChatId}, Text: payload.Text}
+	switch path.Base(r.URL.Path) {
+	case tgapi.EditCmd, tgapi.EditMessageReplyMarkupCmd:
+		// edited in place
+	default:
+		msg.MessageId = s.nextMessageId()
+	}
+	s.writeResult(rw, r, okResponse{Result: msg, Ok: true})
+}
+
+func (s *Server) nextMessageId() uint64 {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.lastMessageId++
+	return s.lastMessageId
+}
+
+// manage logs requests with no message in result
+func (s *Server) manage(rw http.ResponseWriter, r *http.Request) {
+	cts, err := io.ReadAll(r.Body)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	logging.S(r.Context()).Infof("< bot < %s: %s", path.Base(r.URL.Path), string(cts))
+	rw.Write([]byte(`{"ok":true,"result":true}`))
 }
 
 func (s *Server) callback(rw http.ResponseWriter, r *http.Request) {
@@ -149,6 +276,26 @@ func (s *Server) callback(rw http.ResponseWriter, r *http.Request) {
 		return
 	}
 	logging.S(r.Context()).Infof("<- : %s", string(cts))
-	rw.Write([]byte("{}"))
+	rw.Write([]byte(`{"ok":true,"result":true}`))
 	return
 }
+
+func (s *Server) inlineAnswer(rw http.ResponseWriter, r *http.Request) {
+	cts, err := io.ReadAll(r.Body)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	logging.S(r.Context()).Infof("<@ : %s", string(cts))
+	rw.Write([]byte(`{"ok":true,"result":true}`))
+}
+
+func (s *Server) webhook(rw http.ResponseWriter, r *http.Request) {
+	cts, err := io.ReadAll(r.Body)
+	if err != nil {
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	logging.S(r.Context()).Infof("--- set-webhook %s", string(cts))
+	rw.Write([]byte(`{"ok":true,"result":true}`))
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"strings"
+	"sync"
 
 	"github.com/baldisbk/tgbot/internal/impl"
 	"github.com/baldisbk/tgbot/pkg/logging"
@@ -14,25 +15,38 @@ import (
 )
 
 type UserFactory interface {
-	MakeUser(tgapi.User) *impl.User
+	MakeUser(tgapi.Chat, tgapi.User) *impl.User
 }
 
 type cache struct {
+	mx sync.Mutex
 	// TODO: change to LRU cache
-	cache   map[uint64]*impl.User
+	cache   map[pkgcache.Key]*impl.User
+	policy  pkgcache.KeyPolicy
 	factory UserFactory
 	db      DB
 }
 
-func (c *cache) Get(ctx context.Context, user tgapi.User) (pkgcache.User, error) {
-	if u, ok := c.cache[user.Id]; ok {
+// home is chat state belongs to, private chat for states not bound to chat
+func home(key pkgcache.Key, chat tgapi.Chat) tgapi.Chat {
+	if key.ChatId == 0 {
+		return tgapi.Chat{}
+	}
+	return chat
+}
+
+func (c *cache) Get(ctx context.Context, chat tgapi.Chat, user tgapi.User) (pkgcache.User, error) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	key := c.policy.Key(chat, user)
+	if u, ok := c.cache[key]; ok {
 		logging.S(ctx).Debugf("Cached user %v %v", user, u)
 		return u, nil
 	} else {
-		u := c.factory.MakeUser(user)
-		stored, err := c.db.Get(ctx, user.Id)
+		u := c.factory.MakeUser(home(key, chat), user)
+		stored, err := c.db.Get(ctx, key)
 		if err != nil {
-			if xerrors.Is(err, noRowsError) {
+			if !xerrors.Is(err, noRowsError) {
 				return nil, xerrors.Errorf("get: %w", err)
 			}
 			logging.S(ctx).Debugf("New user %v", user)
@@ -44,18 +58,20 @@ func (c *cache) Get(ctx context.Context, user tgapi.User) (pkgcache.User, error)
 		}
 		u.Wake()
 		logging.S(ctx).Debugf("Store user %v", user, u)
-		c.cache[user.Id] = u
+		c.cache[key] = u
 		return u, nil
 	}
 }
 
-func (c *cache) Put(ctx context.Context, tgUser tgapi.User, state pkgcache.User) error {
+func (c *cache) Put(ctx context.Context, chat tgapi.Chat, tgUser tgapi.User, state pkgcache.User) error {
 	content, err := json.Marshal(state)
 	if err != nil {
 		return xerrors.Errorf("marshal: %w", err)
 	}
+	key := c.policy.Key(chat, tgUser)
 	if err := c.db.Add(ctx, StoredUser{
-		Id:       tgUser.Id,
+		ChatId:   key.ChatId,
+		Id:       key.UserId,
 		Name:     tgUser.FirstName,
 		Contents: string(content),
 	}); err != nil {
@@ -64,20 +80,43 @@ func (c *cache) Put(ctx context.Context, tgUser tgapi.User, state pkgcache.User)
 	return nil
 }
 
+// ForChat changes each state kept in chat with f and stores it
+func (c *cache) ForChat(ctx context.Context, chatId int64, f func(*impl.User)) error {
+	c.mx.Lock()
+	var users []*impl.User
+	for _, u := range c.cache {
+		if u.Chat.Id == chatId {
+			users = append(users, u)
+		}
+	}
+	c.mx.Unlock()
+	for _, u := range users {
+		f(u)
+		if err := c.Put(ctx, u.Chat, tgapi.User{Id: u.Id, FirstName: u.Name}, u); err != nil {
+			return xerrors.Errorf("put: %w", err)
+		}
+	}
+	return nil
+}
+
 func (c *cache) Close() { c.db.Close() }
 
 func (c *cache) AttachFactory(ctx context.Context, factory UserFactory) error {
+	c.mx.Lock()
+	defer c.mx.Unlock()
 	c.factory = factory
 	users, err := c.db.List(ctx)
 	if err != nil {
 		return xerrors.Errorf("list: %w", err)
 	}
 	for _, user := range users {
-		u := c.factory.MakeUser(tgapi.User{Id: user.Id, FirstName: user.Name})
+		key := pkgcache.Key{ChatId: user.ChatId, UserId: user.Id}
+		u := c.factory.MakeUser(home(key, tgapi.Chat{Id: user.ChatId}), tgapi.User{Id: user.Id, FirstName: user.Name})
 		if err := json.Unmarshal([]byte(user.Contents), u); err != nil {
 			return xerrors.Errorf("make user: %w", err)
 		}
 		u.Wake()
+		c.cache[key] = u
 	}
 	return nil
 }
@@ -88,9 +127,14 @@ type Config struct {
 	User     string `yaml:"user" env:"TGBOT_DB_USER"`
 	Password string `yaml:"-" env:"TGBOT_DB_PASSWORD"`
 	Database string `yaml:"database" env:"TGBOT_DB_DATABASE"`
+	// state per user, per user in chat or per chat
+	KeyPolicy pkgcache.KeyPolicy `yaml:"key_policy" env:"TGBOT_KEY_POLICY"`
 }
 
 func NewCache(ctx context.Context, cfg Config) (*cache, error) {
+	if !cfg.KeyPolicy.Valid() {
+		return nil, xerrors.Errorf("unknown key policy: %q", cfg.KeyPolicy)
+	}
 	var db DB
 	var err error
 	switch strings.ToLower(cfg.Driver) {
@@ -105,7 +149,8 @@ func NewCache(ctx context.Context, cfg Config) (*cache, error) {
 		return nil, xerrors.Errorf("new db: %w", err)
 	}
 	return &cache{
-		db:    db,
-		cache: map[uint64]*impl.User{},
+		db:     db,
+		policy: cfg.KeyPolicy,
+		cache:  map[pkgcache.Key]*impl.User{},
 	}, nil
 }
@@ -5,6 +5,7 @@ import (
 )
 
 type StoredUser struct {
+	ChatId   int64 // 0 if state is not bound to chat
 	Id       uint64
 	Name     string
 	Contents string
@@ -1,10 +1,14 @@
 package usercache
 
-import "context"
+import (
+	"context"
+
+	pkgcache "github.com/baldisbk/tgbot/pkg/usercache"
+)
 
 type DB interface {
 	Add(ctx context.Context, user StoredUser) error
-	Get(ctx context.Context, id uint64) (*StoredUser, error)
+	Get(ctx context.Context, key pkgcache.Key) (*StoredUser, error)
 	List(ctx context.Context) ([]StoredUser, error)
 	Close()
 }
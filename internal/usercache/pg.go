@@ -7,27 +7,44 @@ import (
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"golang.org/x/xerrors"
+
+	pkgcache "github.com/baldisbk/tgbot/pkg/usercache"
 )
 
 const (
 	schemaPGSQL = `
-CREATE TABLE IF NOT EXISTS users (
-	id INTEGER PRIMARY KEY,
+CREATE TABLE IF NOT EXISTS states (
+	chat_id BIGINT,
+	user_id BIGINT,
 	name TEXT,
-	contents TEXT
+	contents TEXT,
+	PRIMARY KEY (chat_id, user_id)
 );`
 	insertPGSQL = `
-INSERT INTO users (id, name, contents)
-VALUES (?, ?, ?)
-ON CONFLICT (id) DO UPDATE
-SET name = ?, contents = ?;`
+INSERT INTO states (chat_id, user_id, name, contents)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (chat_id, user_id) DO UPDATE
+SET name = $3, contents = $4;`
 	selectPGSQL = `
 SELECT name, contents
-FROM users
-WHERE id=?;`
+FROM states
+WHERE chat_id=$1 AND user_id=$2;`
 	listPGSQL = `
-SELECT name, contents
-FROM users;`  // TODO paging
+SELECT chat_id, user_id, name, contents
+FROM states;` // TODO paging
+
+	// states saved before chat support are keyed by user only
+	legacyPGSQL = `
+CREATE TABLE IF NOT EXISTS users (
+	id INTEGER PRIMARY KEY,
+	name TEXT,
+	contents TEXT
+);`
+	migratePGSQL = `
+INSERT INTO states (chat_id, user_id, name, contents)
+SELECT 0, id, name, contents
+FROM users
+ON CONFLICT DO NOTHING;`
 )
 
 type pgDB struct {
@@ -65,8 +82,10 @@ func (db *pgDB) tx(ctx context.Context, proc func(context.Context, pgx.Tx) error
 
 func (db *pgDB) prepare(ctx context.Context) error {
 	return db.tx(ctx, func(ctx context.Context, tx pgx.Tx) error {
-		if _, err := tx.Exec(ctx, schemaPGSQL); err != nil {
-			return xerrors.Errorf("schema: %w", err)
+		for _, stmt := range []string{schemaPGSQL, legacyPGSQL, migratePGSQL} {
+			if _, err := tx.Exec(ctx, stmt); err != nil {
+				return xerrors.Errorf("schema: %w", err)
+			}
 		}
 		return nil
 	})
@@ -74,18 +93,18 @@ func (db *pgDB) prepare(ctx context.Context) error {
 
 func (db *pgDB) Add(ctx context.Context, user StoredUser) error {
 	return db.tx(ctx, func(ctx context.Context, tx pgx.Tx) error {
-		if _, err := tx.Exec(ctx, insertPGSQL, user.Id, user.Name, user.Contents); err != nil {
+		if _, err := tx.Exec(ctx, insertPGSQL, user.ChatId, user.Id, user.Name, user.Contents); err != nil {
 			return xerrors.Errorf("exec: %w", err)
 		}
 		return nil
 	})
 }
 
-func (db *pgDB) Get(ctx context.Context, id uint64) (*StoredUser, error) {
+func (db *pgDB) Get(ctx context.Context, key pkgcache.Key) (*StoredUser, error) {
 	var rows pgx.Rows
 	err := db.tx(ctx, func(ctx context.Context, tx pgx.Tx) error {
 		var err error
-		rows, err = db.pool.Query(ctx, selectPGSQL, id)
+		rows, err = db.pool.Query(ctx, selectPGSQL, key.ChatId, key.UserId)
 		if err != nil {
 			return xerrors.Errorf("exec: %w", err)
 		}
@@ -106,7 +125,8 @@ func (db *pgDB) Get(ctx context.Context, id uint64) (*StoredUser, error) {
 		return nil, xerrors.Errorf("scan: %w", err)
 	}
 	return &StoredUser{
-		Id:       id,
+		ChatId:   key.ChatId,
+		Id:       key.UserId,
 		Name:     name,
 		Contents: contents,
 	}, nil
@@ -131,12 +151,14 @@ func (db *pgDB) List(ctx context.Context) ([]StoredUser, error) {
 		if err := rows.Err(); err != nil {
 			return nil, xerrors.Errorf("res next: %w", err)
 		}
+		var chatId int64
 		var id uint64
 		var name, contents string
-		if err := rows.Scan(&id, &name, &contents); err != nil {
+		if err := rows.Scan(&chatId, &id, &name, &contents); err != nil {
 			return nil, xerrors.Errorf("scan: %w", err)
 		}
 		users = append(users, StoredUser{
+			ChatId:   chatId,
 			Id:       id,
 			Name:     name,
 			Contents: contents,
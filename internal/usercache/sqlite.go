@@ -6,14 +6,20 @@ import (
 
 	"golang.org/x/xerrors"
 
+	pkgcache "github.com/baldisbk/tgbot/pkg/usercache"
+
 	_ "github.com/mattn/go-sqlite3"
 )
 
 const (
-	schemaSQLite = `CREATE TABLE IF NOT EXISTS users (id INTEGER PRIMARY KEY ON CONFLICT REPLACE, name TEXT, contents TEXT);`
-	insertSQLite = `INSERT INTO users (id, name, contents) VALUES (?, ?, ?);`
-	selectSQLite = `SELECT name, contents FROM users WHERE id=?;`
-	listSQLite   = `SELECT name, contents FROM users;` // TODO paging
+	schemaSQLite = `CREATE TABLE IF NOT EXISTS states (chat_id INTEGER, user_id INTEGER, name TEXT, contents TEXT, PRIMARY KEY (chat_id, user_id) ON CONFLICT REPLACE);`
+	insertSQLite = `INSERT INTO states (chat_id, user_id, name, contents) VALUES (?, ?, ?, ?);`
+	selectSQLite = `SELECT name, contents FROM states WHERE chat_id=? AND user_id=?;`
+	listSQLite   = `SELECT chat_id, user_id, name, contents FROM states;` // TODO paging
+
+	// states saved before chat support are keyed by user only
+	legacySQLite  = `CREATE TABLE IF NOT EXISTS users (id INTEGER PRIMARY KEY ON CONFLICT REPLACE, name TEXT, contents TEXT);`
+	migrateSQLite = `INSERT OR IGNORE INTO states (chat_id, user_id, name, contents) SELECT 0, id, name, contents FROM users;`
 )
 
 func NewSQLiteDB(ctx context.Context, cfg Config) (DB, error) {
@@ -37,8 +43,10 @@ type sqliteDB struct {
 
 func (db *sqliteDB) prepare() error {
 	var err error
-	if _, err = db.sql.Exec(schemaSQLite); err != nil {
-		return xerrors.Errorf("exec: %w", err)
+	for _, stmt := range []string{schemaSQLite, legacySQLite, migrateSQLite} {
+		if _, err = db.sql.Exec(stmt); err != nil {
+			return xerrors.Errorf("exec: %w", err)
+		}
 	}
 	db.ins, err = db.sql.Prepare(insertSQLite)
 	if err != nil {
@@ -60,7 +68,7 @@ func (db *sqliteDB) Add(ctx context.Context, user StoredUser) error {
 	if err != nil {
 		return xerrors.Errorf("tx: %w", err)
 	}
-	if _, err = tx.Stmt(db.ins).Exec(user.Id, user.Name, user.Contents); err != nil {
+	if _, err = tx.Stmt(db.ins).Exec(user.ChatId, user.Id, user.Name, user.Contents); err != nil {
 		tx.Rollback()
 		return xerrors.Errorf("exec: %w", err)
 	}
@@ -70,8 +78,8 @@ func (db *sqliteDB) Add(ctx context.Context, user StoredUser) error {
 	return nil
 }
 
-func (db *sqliteDB) Get(ctx context.Context, id uint64) (*StoredUser, error) {
-	res, err := db.sql.Query(selectSQLite, id)
+func (db *sqliteDB) Get(ctx context.Context, key pkgcache.Key) (*StoredUser, error) {
+	res, err := db.sel.Query(key.ChatId, key.UserId)
 	if err != nil {
 		return nil, xerrors.Errorf("exec: %w", err)
 	}
@@ -87,14 +95,15 @@ func (db *sqliteDB) Get(ctx context.Context, id uint64) (*StoredUser, error) {
 		return nil, xerrors.Errorf("scan: %w", err)
 	}
 	return &StoredUser{
-		Id:       id,
+		ChatId:   key.ChatId,
+		Id:       key.UserId,
 		Name:     name,
 		Contents: contents,
 	}, nil
 }
 
 func (db *sqliteDB) List(ctx context.Context) ([]StoredUser, error) {
-	res, err := db.sql.Query(listSQLite)
+	res, err := db.list.Query()
 	if err != nil {
 		return nil, xerrors.Errorf("exec: %w", err)
 	}
@@ -104,12 +113,14 @@ func (db *sqliteDB) List(ctx context.Context) ([]StoredUser, error) {
 		if err := res.Err(); err != nil {
 			return nil, xerrors.Errorf("res next: %w", err)
 		}
+		var chatId int64
 		var id uint64
 		var name, contents string
-		if err := res.Scan(&id, &name, &contents); err != nil {
+		if err := res.Scan(&chatId, &id, &name, &contents); err != nil {
 			return nil, xerrors.Errorf("scan: %w", err)
 		}
 		users = append(users, StoredUser{
+			ChatId:   chatId,
 			Id:       id,
 			Name:     name,
 			Contents: contents,
@@ -121,5 +132,6 @@ func (db *sqliteDB) List(ctx context.Context) ([]StoredUser, error) {
 func (db *sqliteDB) Close() {
 	db.ins.Close()
 	db.sel.Close()
+	db.list.Close()
 	db.sql.Close()
 }
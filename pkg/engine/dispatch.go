@@ -0,0 +1,60 @@
+package engine
+
+import (
+	"context"
+
+	"golang.org/x/xerrors"
+
+	"github.com/baldisbk/tgbot/pkg/logging"
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
+
+// Dispatch passes update contents to the engine as a signal
+func Dispatch(ctx context.Context, e Engine, upd tgapi.Update) error {
+	switch {
+	case upd.Message != nil:
+		ctx = logging.WithTag(ctx, "EVENT", upd.Message.UUID)
+		if err := e.Receive(ctx, upd.Message); err != nil {
+			return xerrors.Errorf("receive message (%#v): %w", upd.Message, err)
+		}
+	case upd.CallbackQuery != nil:
+		ctx = logging.WithTag(ctx, "EVENT", upd.CallbackQuery.UUID)
+		if err := e.Receive(ctx, upd.CallbackQuery); err != nil {
+			return xerrors.Errorf("receive callback (%#v): %w", upd.CallbackQuery, err)
+		}
+	case upd.InlineQuery != nil:
+		ctx = logging.WithTag(ctx, "EVENT", upd.InlineQuery.UUID)
+		if err := e.Receive(ctx, upd.InlineQuery); err != nil {
+			return xerrors.Errorf("receive inline query (%#v): %w", upd.InlineQuery, err)
+		}
+	case upd.ChosenInlineResult != nil:
+		ctx = logging.WithTag(ctx, "EVENT", upd.ChosenInlineResult.UUID)
+		if err := e.Receive(ctx, upd.ChosenInlineResult); err != nil {
+			return xerrors.Errorf("receive inline result (%#v): %w", upd.ChosenInlineResult, err)
+		}
+	case upd.EditedMessage != nil:
+		ctx = logging.WithTag(ctx, "EVENT", upd.EditedMessage.UUID)
+		if err := e.Receive(ctx, upd.EditedMessage); err != nil {
+			return xerrors.Errorf("receive edited message (%#v): %w", upd.EditedMessage, err)
+		}
+	case upd.MyChatMember != nil:
+		ctx = logging.WithTag(ctx, "EVENT", upd.MyChatMember.UUID)
+		if err := e.Receive(ctx, upd.MyChatMember); err != nil {
+			return xerrors.Errorf("receive bot member (%#v): %w", upd.MyChatMember, err)
+		}
+	case upd.ChatMember != nil:
+		ctx = logging.WithTag(ctx, "EVENT", upd.ChatMember.UUID)
+		if err := e.Receive(ctx, upd.ChatMember); err != nil {
+			return xerrors.Errorf("receive chat member (%#v): %w", upd.ChatMember, err)
+		}
+	case upd.PollAnswer != nil:
+		ctx = logging.WithTag(ctx, "EVENT", upd.PollAnswer.UUID)
+		if err := e.Receive(ctx, upd.PollAnswer); err != nil {
+			return xerrors.Errorf("receive poll answer (%#v): %w", upd.PollAnswer, err)
+		}
+	case upd.Poll != nil:
+		// no user to route it to, answers come separately
+		logging.S(ctx).Debugf("Poll %s updated: %d votes", upd.Poll.Id, upd.Poll.TotalVoterCount)
+	}
+	return nil
+}
@@ -3,6 +3,9 @@ package engine
 import (
 	"context"
 	"strconv"
+	"time"
+
+	"github.com/jonboulle/clockwork"
 
 	"github.com/baldisbk/tgbot/pkg/logging"
 	"github.com/baldisbk/tgbot/pkg/tgapi"
@@ -17,55 +20,122 @@ type Engine interface {
 
 type Signal interface {
 	User() tgapi.User
+	// chat signal came from, state may be kept per chat
+	Origin() tgapi.Chat
 	Message() interface{}
 	PreProcess(ctx context.Context, client tgapi.TGClient) error
 	PostProcess(ctx context.Context, client tgapi.TGClient) error
 }
 
+// replier is signal with chat to reply to
+type replier interface {
+	Recipient() tgapi.Recipient
+}
+
+type Config struct {
+	// "typing" is shown if processing takes longer, disabled if 0
+	TypingDelay time.Duration `yaml:"typing_delay"`
+	// repeat "typing" while still processing, action lasts 5s
+	TypingPeriod time.Duration `yaml:"typing_period"`
+}
+
+const defaultTypingPeriod = 4 * time.Second
+
 type engine struct {
 	client tgapi.TGClient
 	cache  usercache.UserCache
+
+	config Config
+	clock  clockwork.Clock
 }
 
-func NewEngine(client tgapi.TGClient, cache usercache.UserCache) *engine {
+func NewEngine(cfg Config, client tgapi.TGClient, cache usercache.UserCache) *engine {
+	return newEngine(cfg, clockwork.NewRealClock(), client, cache)
+}
+
+func newEngine(cfg Config, clock clockwork.Clock, client tgapi.TGClient, cache usercache.UserCache) *engine {
+	if cfg.TypingPeriod == 0 {
+		cfg.TypingPeriod = defaultTypingPeriod
+	}
 	return &engine{
 		cache:  cache,
 		client: client,
+		config: cfg,
+		clock:  clock,
 	}
 }
 
 func (e *engine) Receive(ctx context.Context, signal Signal) error {
 	tgUser := signal.User()
+	chat := signal.Origin()
 	ctx = logging.WithTag(ctx, "USER", strconv.FormatUint(tgUser.Id, 16))
+	if chat.Id != int64(tgUser.Id) {
+		ctx = logging.WithTag(ctx, "CHAT", strconv.FormatInt(chat.Id, 16))
+	}
 	var err error
 	var user usercache.User
-	if user, err = e.cache.Get(ctx, tgUser); err != nil {
+	if user, err = e.cache.Get(ctx, chat, tgUser); err != nil {
 		// database problem
 		return xerrors.Errorf("get user from cache: %w", err)
 	}
 	logging.S(ctx).Infof("Received signal (%#v) for user %v", signal, user)
 	if err := signal.PreProcess(ctx, e.client); err != nil {
 		// retriable (network)
-		return xerrors.Errorf("preprocess signal: %w", err)
+		return xerrors.Errorf("preprocess signal: %w", classify(err))
 	}
+	stopTyping := e.typing(ctx, signal)
 	rsp, err := user.Run(ctx, signal.Message())
+	stopTyping()
 	if err != nil {
+		// still answer, e.g. callback button spins until then
+		if err := signal.PostProcess(ctx, e.client); err != nil {
+			logging.S(ctx).Warnf("Postprocess failed signal: %s", err)
+		}
 		// retriable (network)
-		return xerrors.Errorf("process signal: %w", err)
+		return xerrors.Errorf("process signal: %w", classify(err))
 	}
 	if err := signal.PostProcess(ctx, e.client); err != nil {
 		// retriable (network)
-		return xerrors.Errorf("postprocess signal: %w", err)
+		return xerrors.Errorf("postprocess signal: %w", classify(err))
 	}
 
 	if err := user.UpdateState(ctx, rsp); err != nil {
 		// bad response
 		return xerrors.Errorf("update user state: %w", err)
 	}
-	if err := e.cache.Put(ctx, tgUser, user); err != nil {
+	if err := e.cache.Put(ctx, chat, tgUser, user); err != nil {
 		// database problem
 		return xerrors.Errorf("put user to cache: %w", err)
 	}
 
 	return nil
 }
+
+// typing shows "typing" in chat of signal until stopped if it takes too long
+func (e *engine) typing(ctx context.Context, signal Signal) (stop func()) {
+	to, ok := signal.(replier)
+	if e.config.TypingDelay == 0 || !ok {
+		return func() {}
+	}
+	// stop cancels action in flight too, no need to wait for it
+	ctx, cancel := context.WithCancel(ctx)
+	go func() {
+		wait := e.config.TypingDelay
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-e.clock.After(wait):
+			}
+			if ctx.Err() != nil {
+				return
+			}
+			if err := e.client.SendChatAction(ctx, to.Recipient(), tgapi.TypingAction); err != nil && ctx.Err() == nil {
+				// cosmetic, just log it
+				logging.S(ctx).Warnf("Send chat action: %s", err)
+			}
+			wait = e.config.TypingPeriod
+		}
+	}()
+	return cancel
+}
@@ -3,7 +3,9 @@ package engine
 import (
 	"context"
 	"testing"
+	"time"
 
+	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 	"golang.org/x/xerrors"
@@ -19,6 +21,13 @@ func TestEngine(t *testing.T) {
 	saveErr := xerrors.New("update")
 	getErr := xerrors.New("get")
 	putErr := xerrors.New("put")
+	floodErr := &tgapi.Error{Code: 429, Description: "Too Many Requests", RetryAfter: time.Second}
+	blockedErr := &tgapi.Error{Code: 403, Description: "Forbidden: bot was blocked by the user"}
+	notModifiedErr := &tgapi.Error{Code: 400, Description: "Bad Request: message is not modified"}
+
+	tgUser := tgapi.User{Id: 1}
+	chat := tgapi.Chat{Id: -100, Type: tgapi.GroupChat}
+	query := &tgapi.CallbackQuery{Id: "q", From: tgUser, Source: &tgapi.Message{Chat: chat}}
 
 	testCases := []struct {
 		desc            string
@@ -27,6 +36,8 @@ func TestEngine(t *testing.T) {
 		runErr, saveErr error
 		getErr, putErr  error
 		expErr          error
+		// real signal, answered by client
+		query *tgapi.CallbackQuery
 	}{
 		{
 			desc: "ok",
@@ -51,6 +62,37 @@ func TestEngine(t *testing.T) {
 			runErr: runErr,
 			expErr: runErr,
 		},
+		{
+			desc:   "run-err-callback",
+			req:    query,
+			runErr: runErr,
+			expErr: runErr,
+			query:  query,
+		},
+		{
+			desc:   "run-flood",
+			req:    "A",
+			runErr: floodErr,
+			expErr: RetriableError,
+		},
+		{
+			desc:   "pre-flood",
+			req:    "A",
+			preErr: floodErr,
+			expErr: floodErr,
+		},
+		{
+			desc:   "run-blocked",
+			req:    "A",
+			runErr: blockedErr,
+			expErr: FatalError,
+		},
+		{
+			desc:   "run-not-modified",
+			req:    "A",
+			runErr: notModifiedErr,
+			expErr: tgapi.NotModifiedError,
+		},
 		{
 			desc:    "save-err",
 			req:     "A",
@@ -75,29 +117,183 @@ func TestEngine(t *testing.T) {
 			assert := require.New(t)
 
 			client := tgapi.NewMock()
+			client.On("AnswerCallback", mock.Anything, tgapi.AnswerCallback{CallbackQueryId: "q"}).Return(nil)
 
 			user := usercache.NewUserMock()
 			user.On("Run", mock.Anything, c.req).Return(c.rsp, c.runErr)
 			user.On("UpdateState", mock.Anything, c.rsp).Return(c.saveErr)
 
 			cache := usercache.NewCacheMock()
-			cache.On("Get", mock.Anything, tgapi.User{}).Return(user, c.getErr)
-			cache.On("Put", mock.Anything, tgapi.User{}, user).Return(c.putErr)
+			cache.On("Get", mock.Anything, chat, tgUser).Return(user, c.getErr)
+			cache.On("Put", mock.Anything, chat, tgUser, user).Return(c.putErr)
 
 			signal := NewSignalMock()
-			signal.On("User").Return(tgapi.User{})
+			signal.On("User").Return(tgUser)
+			signal.On("Origin").Return(chat)
 			signal.On("Message").Return(c.req)
 			signal.On("PreProcess", mock.Anything, client).Return(c.preErr)
 			signal.On("PostProcess", mock.Anything, client).Return(c.postErr)
 
-			engine := NewEngine(client, cache)
-			err := engine.Receive(context.Background(), signal)
+			engine := NewEngine(Config{}, client, cache)
+			var err error
+			if c.query != nil {
+				err = engine.Receive(context.Background(), c.query)
+			} else {
+				err = engine.Receive(context.Background(), signal)
+			}
 
 			if c.expErr == nil {
 				assert.NoError(err)
 			} else {
 				assert.True(xerrors.Is(err, c.expErr))
 			}
+			switch {
+			case c.query != nil:
+				client.AssertCalled(t, "AnswerCallback", mock.Anything, tgapi.AnswerCallback{CallbackQueryId: "q"})
+			case c.runErr != nil:
+				// answered even if processing failed
+				signal.AssertCalled(t, "PostProcess", mock.Anything, client)
+			}
+		})
+	}
+}
+
+// replierMock is signal with chat to reply to
+type replierMock struct {
+	*signalMock
+}
+
+func (r replierMock) Recipient() tgapi.Recipient { return tgapi.ToChat(-100) }
+
+func TestEngineTyping(t *testing.T) {
+	const delay, period = time.Second, 4 * time.Second
+	testCases := []struct {
+		desc    string
+		ticks   int // periods after delay
+		replier bool
+		actions int
+	}{
+		{
+			desc:    "fast",
+			ticks:   -1,
+			replier: true,
+		},
+		{
+			desc:    "slow",
+			ticks:   0,
+			replier: true,
+			actions: 1,
+		},
+		{
+			desc:    "very slow",
+			ticks:   2,
+			replier: true,
+			actions: 3,
+		},
+		{
+			desc:  "nowhere to type",
+			ticks: -1,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			clock := clockwork.NewFakeClock()
+			sent := make(chan struct{})
+
+			client := tgapi.NewMock()
+			client.On("AnswerCallback", mock.Anything, tgapi.AnswerCallback{CallbackQueryId: "q"}).Return(nil)
+			client.On("SendChatAction", mock.Anything, tgapi.ToChat(-100), tgapi.TypingAction).
+				Return(nil).
+				Run(func(mock.Arguments) { sent <- struct{}{} })
+
+			user := usercache.NewUserMock()
+			user.On("Run", mock.Anything, "A").Return("B", nil).Run(func(mock.Arguments) {
+				if c.ticks < 0 {
+					return
+				}
+				clock.BlockUntil(1)
+				clock.Advance(delay)
+				<-sent
+				for i := 0; i < c.ticks; i++ {
+					clock.BlockUntil(1)
+					clock.Advance(period)
+					<-sent
+				}
+			})
+			user.On("UpdateState", mock.Anything, "B").Return(nil)
+
+			cache := usercache.NewCacheMock()
+			cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(user, nil)
+			cache.On("Put", mock.Anything, mock.Anything, mock.Anything, user).Return(nil)
+
+			signal := NewSignalMock()
+			signal.On("User").Return(tgapi.User{Id: 1})
+			signal.On("Origin").Return(tgapi.Chat{Id: -100, Type: tgapi.GroupChat})
+			signal.On("Message").Return("A")
+			signal.On("PreProcess", mock.Anything, client).Return(nil)
+			signal.On("PostProcess", mock.Anything, client).Return(nil)
+
+			engine := newEngine(Config{TypingDelay: delay, TypingPeriod: period}, clock, client, cache)
+			var err error
+			if c.replier {
+				err = engine.Receive(context.Background(), replierMock{signal})
+			} else {
+				err = engine.Receive(context.Background(), signal)
+			}
+			assert.NoError(err)
+			client.AssertNumberOfCalls(t, "SendChatAction", c.actions)
 		})
 	}
 }
+
+func TestEngineTypingStop(t *testing.T) {
+	assert := require.New(t)
+	clock := clockwork.NewFakeClock()
+	sent := make(chan struct{})
+	cancelled := make(chan struct{})
+
+	// action hangs until stopped
+	client := tgapi.NewMock()
+	client.On("SendChatAction", mock.Anything, tgapi.ToChat(-100), tgapi.TypingAction).
+		Return(nil).
+		Run(func(args mock.Arguments) {
+			sent <- struct{}{}
+			<-args[0].(context.Context).Done()
+			close(cancelled)
+		})
+
+	user := usercache.NewUserMock()
+	user.On("Run", mock.Anything, "A").Return("B", nil).Run(func(mock.Arguments) {
+		clock.BlockUntil(1)
+		clock.Advance(time.Second)
+		<-sent
+	})
+	user.On("UpdateState", mock.Anything, "B").Return(nil)
+
+	cache := usercache.NewCacheMock()
+	cache.On("Get", mock.Anything, mock.Anything, mock.Anything).Return(user, nil)
+	cache.On("Put", mock.Anything, mock.Anything, mock.Anything, user).Return(nil)
+
+	signal := NewSignalMock()
+	signal.On("User").Return(tgapi.User{Id: 1})
+	signal.On("Origin").Return(tgapi.Chat{Id: -100, Type: tgapi.GroupChat})
+	signal.On("Message").Return("A")
+	signal.On("PreProcess", mock.Anything, client).Return(nil)
+	signal.On("PostProcess", mock.Anything, client).Return(nil)
+
+	engine := newEngine(Config{TypingDelay: time.Second, TypingPeriod: 4 * time.Second}, clock, client, cache)
+	received := make(chan error)
+	go func() { received <- engine.Receive(context.Background(), replierMock{signal}) }()
+	select {
+	case err := <-received:
+		assert.NoError(err)
+	case <-time.After(time.Second):
+		t.Fatal("waits for chat action")
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("chat action not cancelled")
+	}
+}
@@ -1,6 +1,12 @@
 package engine
 
-import "golang.org/x/xerrors"
+import (
+	"net"
+
+	"golang.org/x/xerrors"
+
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
 
 // TODO make them types
 var (
@@ -9,3 +15,31 @@ var (
 	RetriableError  = xerrors.New("retriable")
 	FatalError      = xerrors.New("fatal")
 )
+
+// classified matches its class with xerrors.Is, keeping original error
+type classified struct {
+	err   error
+	class error
+}
+
+func (e *classified) Error() string        { return e.err.Error() }
+func (e *classified) Unwrap() error        { return e.err }
+func (e *classified) Is(target error) bool { return target == e.class }
+
+// classify marks Bot API and network errors as RetriableError or FatalError
+func classify(err error) error {
+	var apiErr *tgapi.Error
+	var netErr net.Error
+	switch {
+	case xerrors.As(err, &apiErr):
+		if apiErr.Retriable() {
+			return &classified{err: err, class: RetriableError}
+		}
+		if apiErr.Fatal() {
+			return &classified{err: err, class: FatalError}
+		}
+	case xerrors.As(err, &netErr):
+		return &classified{err: err, class: RetriableError}
+	}
+	return err
+}
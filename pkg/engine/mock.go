@@ -35,6 +35,10 @@ func (u *signalMock) User() tgapi.User {
 	return u.Called()[0].(tgapi.User)
 }
 
+func (u *signalMock) Origin() tgapi.Chat {
+	return u.Called()[0].(tgapi.Chat)
+}
+
 func (u *signalMock) Message() interface{} {
 	return u.Called()[0]
 }
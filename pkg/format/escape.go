@@ -0,0 +1,51 @@
+package format
+
+import (
+	"html"
+	"strings"
+)
+
+// ======== HTML ========
+
+// EscapeHTML makes user text safe to put into HTML parse mode message
+func EscapeHTML(s string) string {
+	return html.EscapeString(s)
+}
+
+// spans for HTML parse mode, contents are escaped
+func Bold(s string) string          { return "<b>" + EscapeHTML(s) + "</b>" }
+func Italic(s string) string        { return "<i>" + EscapeHTML(s) + "</i>" }
+func Underline(s string) string     { return "<u>" + EscapeHTML(s) + "</u>" }
+func Strikethrough(s string) string { return "<s>" + EscapeHTML(s) + "</s>" }
+func Code(s string) string          { return "<code>" + EscapeHTML(s) + "</code>" }
+
+func Link(s, url string) string {
+	return `<a href="` + EscapeHTML(url) + `">` + EscapeHTML(s) + "</a>"
+}
+
+// ======== MarkdownV2 ========
+
+var (
+	markdownReplacer = strings.NewReplacer(
+		`\`, `\\`, "_", `\_`, "*", `\*`, "[", `\[`, "]", `\]`, "(", `\(`, ")", `\)`,
+		"~", `\~`, "`", "\\`", ">", `\>`, "#", `\#`, "+", `\+`, "-", `\-`, "=", `\=`,
+		"|", `\|`, "{", `\{`, "}", `\}`, ".", `\.`, "!", `\!`,
+	)
+	markdownCodeReplacer = strings.NewReplacer(`\`, `\\`, "`", "\\`")
+	markdownURLReplacer  = strings.NewReplacer(`\`, `\\`, ")", `\)`)
+)
+
+// EscapeMarkdownV2 makes user text safe to put into MarkdownV2 parse mode message
+func EscapeMarkdownV2(s string) string {
+	return markdownReplacer.Replace(s)
+}
+
+// EscapeMarkdownV2Code escapes contents of code and pre spans
+func EscapeMarkdownV2Code(s string) string {
+	return markdownCodeReplacer.Replace(s)
+}
+
+// EscapeMarkdownV2URL escapes URL part of inline link
+func EscapeMarkdownV2URL(s string) string {
+	return markdownURLReplacer.Replace(s)
+}
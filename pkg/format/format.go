@@ -0,0 +1,88 @@
+package format
+
+import (
+	"strings"
+	"unicode/utf16"
+
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
+
+// entity types, see MessageEntity
+const (
+	BoldEntity          = "bold"
+	ItalicEntity        = "italic"
+	UnderlineEntity     = "underline"
+	StrikethroughEntity = "strikethrough"
+	SpoilerEntity       = "spoiler"
+	CodeEntity          = "code"
+	PreEntity           = "pre"
+	TextLinkEntity      = "text_link"
+	TextMentionEntity   = "text_mention"
+)
+
+// Builder makes text with explicit entities, no escaping needed
+type Builder struct {
+	text     strings.Builder
+	offset   int // in UTF-16 units
+	entities []tgapi.MessageEntity
+}
+
+// Plain appends text without formatting
+func (b *Builder) Plain(s string) *Builder {
+	b.text.WriteString(s)
+	b.offset += utf16Len(s)
+	return b
+}
+
+func (b *Builder) Bold(s string) *Builder { return b.span(s, tgapi.MessageEntity{Type: BoldEntity}) }
+func (b *Builder) Italic(s string) *Builder {
+	return b.span(s, tgapi.MessageEntity{Type: ItalicEntity})
+}
+func (b *Builder) Underline(s string) *Builder {
+	return b.span(s, tgapi.MessageEntity{Type: UnderlineEntity})
+}
+func (b *Builder) Strikethrough(s string) *Builder {
+	return b.span(s, tgapi.MessageEntity{Type: StrikethroughEntity})
+}
+func (b *Builder) Spoiler(s string) *Builder {
+	return b.span(s, tgapi.MessageEntity{Type: SpoilerEntity})
+}
+func (b *Builder) Code(s string) *Builder { return b.span(s, tgapi.MessageEntity{Type: CodeEntity}) }
+
+// Pre appends code block, language may be empty
+func (b *Builder) Pre(s, language string) *Builder {
+	return b.span(s, tgapi.MessageEntity{Type: PreEntity, Language: language})
+}
+
+func (b *Builder) Link(s, url string) *Builder {
+	return b.span(s, tgapi.MessageEntity{Type: TextLinkEntity, URL: url})
+}
+
+// Mention links user without username
+func (b *Builder) Mention(s string, user tgapi.User) *Builder {
+	return b.span(s, tgapi.MessageEntity{Type: TextMentionEntity, User: &user})
+}
+
+func (b *Builder) span(s string, entity tgapi.MessageEntity) *Builder {
+	entity.Offset = b.offset
+	entity.Length = utf16Len(s)
+	if entity.Length != 0 {
+		b.entities = append(b.entities, entity)
+	}
+	return b.Plain(s)
+}
+
+func (b *Builder) Text() tgapi.Text {
+	return tgapi.Text{
+		Text:     b.text.String(),
+		Entities: append([]tgapi.MessageEntity(nil), b.entities...),
+	}
+}
+
+func utf16Len(s string) int {
+	var res int
+	for _, r := range s {
+		res += utf16.RuneLen(r)
+	}
+	return res
+}
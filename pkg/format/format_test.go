@@ -0,0 +1,107 @@
+package format
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
+
+func TestBuilder(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		build func(b *Builder)
+		text  tgapi.Text
+	}{
+		{
+			desc:  "plain",
+			build: func(b *Builder) { b.Plain("hello") },
+			text:  tgapi.Text{Text: "hello"},
+		},
+		{
+			desc: "spans",
+			build: func(b *Builder) {
+				b.Bold("<b>name</b>").Plain(": ").Italic("*desc*").Plain(" ").Link("here", "http://x")
+			},
+			text: tgapi.Text{
+				Text: "<b>name</b>: *desc* here",
+				Entities: []tgapi.MessageEntity{
+					{Type: BoldEntity, Offset: 0, Length: 11},
+					{Type: ItalicEntity, Offset: 13, Length: 6},
+					{Type: TextLinkEntity, Offset: 20, Length: 4, URL: "http://x"},
+				},
+			},
+		},
+		{
+			desc: "utf16",
+			build: func(b *Builder) {
+				b.Plain("😀 привет ").Code("x").Pre("y", "go")
+			},
+			text: tgapi.Text{
+				Text: "😀 привет xy",
+				Entities: []tgapi.MessageEntity{
+					{Type: CodeEntity, Offset: 10, Length: 1},
+					{Type: PreEntity, Offset: 11, Length: 1, Language: "go"},
+				},
+			},
+		},
+		{
+			desc:  "empty span",
+			build: func(b *Builder) { b.Bold("").Plain("x") },
+			text:  tgapi.Text{Text: "x"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			var b Builder
+			tC.build(&b)
+			require.Equal(t, tC.text, b.Text())
+		})
+	}
+}
+
+func TestEscape(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		escape   func(string) string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "html",
+			escape:   EscapeHTML,
+			input:    `<b>Tom & "Jerry"</b>`,
+			expected: `&lt;b&gt;Tom &amp; &#34;Jerry&#34;&lt;/b&gt;`,
+		},
+		{
+			desc:     "html bold",
+			escape:   Bold,
+			input:    `a<b`,
+			expected: `<b>a&lt;b</b>`,
+		},
+		{
+			desc:     "markdown",
+			escape:   EscapeMarkdownV2,
+			input:    `*run_5k* [fast] (v1.0)! \o/`,
+			expected: `\*run\_5k\* \[fast\] \(v1\.0\)\! \\o/`,
+		},
+		{
+			desc:     "markdown code",
+			escape:   EscapeMarkdownV2Code,
+			input:    "a`b\\c*d",
+			expected: "a\\`b\\\\c*d",
+		},
+		{
+			desc:     "markdown url",
+			escape:   EscapeMarkdownV2URL,
+			input:    `http://x/(y)`,
+			expected: `http://x/(y\)`,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			require.Equal(t, tC.expected, tC.escape(tC.input))
+		})
+	}
+}
@@ -4,8 +4,12 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"fmt"
 	"io"
+	"mime/multipart"
 	"net/http"
+	"net/textproto"
+	"strings"
 
 	"golang.org/x/xerrors"
 
@@ -13,8 +17,25 @@ import (
 )
 
 type BaseClient struct {
-	Client *http.Client
-	Path   string
+	Client      *http.Client
+	Path        string
+	Redactor    *Redactor
+	Middlewares []Middleware // first is outermost
+}
+
+// non-OK http response
+type StatusError struct {
+	Code int
+	Body []byte
+}
+
+func (e *StatusError) Error() string { return fmt.Sprintf("http status: %d", e.Code) }
+
+// file to be sent in multipart form
+type FormFile struct {
+	Name        string
+	ContentType string // application/octet-stream if empty
+	Reader      io.Reader
 }
 
 func (c *BaseClient) Request(ctx context.Context, httpmethod, apimethod string, input interface{}, output interface{}) error {
@@ -24,23 +45,123 @@ func (c *BaseClient) Request(ctx context.Context, httpmethod, apimethod string,
 	}
 	req, err := http.NewRequestWithContext(ctx, httpmethod, c.Path+apimethod, bytes.NewBuffer(body))
 	if err != nil {
-		return xerrors.Errorf("make req: %w", err)
+		return xerrors.Errorf("make req: %w", c.Redactor.Error(err))
 	}
 	req.Header.Add("Content-Type", "application/json")
-	// TODO middleware
-	logging.S(ctx).Debugf("HTTP REQ: %s, %s, %s", req.URL.String(), req.Method, string(body))
-	rsp, err := c.Client.Do(req)
+	logging.S(ctx).Debugf("HTTP REQ: %s, %s, %s",
+		c.Redactor.String(req.URL.String()), req.Method, c.Redactor.Body(body))
+	return c.do(ctx, req, output)
+}
+
+// RequestForm sends input fields and files as multipart/form-data.
+// Non-string fields of input are sent JSON-serialized.
+func (c *BaseClient) RequestForm(ctx context.Context, httpmethod, apimethod string, input interface{}, files map[string]FormFile, output interface{}) error {
+	fields, err := formFields(input)
+	if err != nil {
+		return xerrors.Errorf("fields: %w", err)
+	}
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := writer.WriteField(name, value); err != nil {
+			return xerrors.Errorf("write field %s: %w", name, err)
+		}
+	}
+	for name, file := range files {
+		contentType := file.ContentType
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		header := textproto.MIMEHeader{}
+		header.Set("Content-Disposition", fmt.Sprintf(`form-data; name="%s"; filename="%s"`,
+			escapeQuotes(name), escapeQuotes(file.Name)))
+		header.Set("Content-Type", contentType)
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			return xerrors.Errorf("create file %s: %w", name, err)
+		}
+		if _, err := io.Copy(part, file.Reader); err != nil {
+			return xerrors.Errorf("write file %s: %w", name, err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return xerrors.Errorf("close form: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, httpmethod, c.Path+apimethod, &body)
+	if err != nil {
+		return xerrors.Errorf("make req: %w", c.Redactor.Error(err))
+	}
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	logging.S(ctx).Debugf("HTTP REQ: %s, %s, %v + %d files",
+		c.Redactor.String(req.URL.String()), req.Method, c.Redactor.Fields(fields), len(files))
+	return c.do(ctx, req, output)
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string { return quoteEscaper.Replace(s) }
+
+func formFields(input interface{}) (map[string]string, error) {
+	fields := map[string]string{}
+	if input == nil {
+		return fields, nil
+	}
+	body, err := json.Marshal(input)
+	if err != nil {
+		return nil, xerrors.Errorf("marshal: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(body, &raw); err != nil {
+		return nil, xerrors.Errorf("unmarshal: %w", err)
+	}
+	for name, value := range raw {
+		var str string
+		if err := json.Unmarshal(value, &str); err == nil {
+			fields[name] = str
+		} else {
+			fields[name] = string(value)
+		}
+	}
+	return fields, nil
+}
+
+// Get streams response body of GET request to full url, caller must close it
+func (c *BaseClient) Get(ctx context.Context, url string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
-		return xerrors.Errorf("request: %w", err)
+		return nil, xerrors.Errorf("make req: %w", c.Redactor.Error(err))
+	}
+	logging.S(ctx).Debugf("HTTP GET: %s", c.Redactor.String(url))
+	rsp, err := chain(c.Client.Do, c.Middlewares)(req)
+	if err != nil {
+		return nil, xerrors.Errorf("request: %w", c.Redactor.Error(err))
 	}
 	if rsp.StatusCode != http.StatusOK {
-		return xerrors.Errorf("http status: %d", rsp.StatusCode)
+		defer rsp.Body.Close()
+		body, err := io.ReadAll(rsp.Body)
+		if err != nil {
+			return nil, xerrors.Errorf("read rsp: %w", err)
+		}
+		logging.S(ctx).Debugf("HTTP RSP: %d, %s", rsp.StatusCode, c.Redactor.Body(body))
+		return nil, &StatusError{Code: rsp.StatusCode, Body: body}
+	}
+	return rsp.Body, nil
+}
+
+func (c *BaseClient) do(ctx context.Context, req *http.Request, output interface{}) error {
+	rsp, err := chain(c.Client.Do, c.Middlewares)(req)
+	if err != nil {
+		return xerrors.Errorf("request: %w", c.Redactor.Error(err))
 	}
-	body, err = io.ReadAll(rsp.Body)
+	defer rsp.Body.Close()
+	body, err := io.ReadAll(rsp.Body)
 	if err != nil {
 		return xerrors.Errorf("read rsp: %w", err)
 	}
-	logging.S(ctx).Debugf("HTTP RSP: %s", string(body))
+	logging.S(ctx).Debugf("HTTP RSP: %d, %s", rsp.StatusCode, c.Redactor.Body(body))
+	if rsp.StatusCode != http.StatusOK {
+		return &StatusError{Code: rsp.StatusCode, Body: body}
+	}
 	if output == nil {
 		return nil
 	}
@@ -0,0 +1,214 @@
+package httputils
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/baldisbk/tgbot/pkg/logging"
+)
+
+// Doer sends http request, like http.Client.Do
+type Doer func(*http.Request) (*http.Response, error)
+
+// Middleware wraps Doer with some extra behaviour
+type Middleware func(next Doer) Doer
+
+type MiddlewareConfig struct {
+	Logging bool        `yaml:"logging"`
+	Retry   RetryConfig `yaml:"retry"`
+	Faults  FaultConfig `yaml:"faults"`
+}
+
+// Stack makes middlewares from config, outermost first:
+// retries see each attempt logged and injected faults
+func (cfg MiddlewareConfig) Stack(retryAfter RetryAfterFunc) []Middleware {
+	var res []Middleware
+	if cfg.Retry.Attempts > 1 {
+		res = append(res, Retry(cfg.Retry, retryAfter))
+	}
+	if cfg.Logging {
+		res = append(res, Logging())
+	}
+	if cfg.Faults.ErrorRate > 0 || cfg.Faults.Delay > 0 {
+		res = append(res, Faults(cfg.Faults))
+	}
+	return res
+}
+
+// chain wraps client call with middlewares, first is outermost
+func chain(do Doer, middlewares []Middleware) Doer {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		do = middlewares[i](do)
+	}
+	return do
+}
+
+// ======== Logging ========
+
+// Logging logs every attempt with its result and duration
+func Logging() Middleware {
+	return func(next Doer) Doer {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			rsp, err := next(req)
+			method := path.Base(req.URL.Path)
+			if err != nil {
+				logging.S(req.Context()).Debugf("HTTP %s failed in %s: %v", method, time.Since(start), err)
+			} else {
+				logging.S(req.Context()).Debugf("HTTP %s: %d in %s", method, rsp.StatusCode, time.Since(start))
+			}
+			return rsp, err
+		}
+	}
+}
+
+// ======== Metrics ========
+
+// Observer receives API method, http status (0 on failure) and duration of every attempt
+type Observer func(method string, status int, duration time.Duration, err error)
+
+func Metrics(observe Observer) Middleware {
+	return func(next Doer) Doer {
+		return func(req *http.Request) (*http.Response, error) {
+			start := time.Now()
+			rsp, err := next(req)
+			var status int
+			if rsp != nil {
+				status = rsp.StatusCode
+			}
+			observe(path.Base(req.URL.Path), status, time.Since(start), err)
+			return rsp, err
+		}
+	}
+}
+
+// ======== Retries ========
+
+type RetryConfig struct {
+	Attempts int           `yaml:"attempts"`  // including first one
+	MinDelay time.Duration `yaml:"min_delay"` // doubled after each attempt
+	MaxDelay time.Duration `yaml:"max_delay"` // longer waits are left to caller
+}
+
+// RetryAfterFunc extracts delay requested by server, 0 if none
+type RetryAfterFunc func(rsp *http.Response, body []byte) time.Duration
+
+// HeaderRetryAfter reads standard Retry-After header
+func HeaderRetryAfter(rsp *http.Response, body []byte) time.Duration {
+	if secs, err := strconv.Atoi(rsp.Header.Get("Retry-After")); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	return 0
+}
+
+// Retry repeats requests failed on network or with 429 and 5xx statuses
+func Retry(cfg RetryConfig, retryAfter RetryAfterFunc) Middleware {
+	if retryAfter == nil {
+		retryAfter = HeaderRetryAfter
+	}
+	return func(next Doer) Doer {
+		return func(req *http.Request) (*http.Response, error) {
+			delay := cfg.MinDelay
+			for attempt := 1; ; attempt++ {
+				rsp, err := next(req)
+				if attempt >= cfg.Attempts || req.Context().Err() != nil {
+					return rsp, err
+				}
+				wait := delay
+				if err == nil {
+					if rsp.StatusCode != http.StatusTooManyRequests && rsp.StatusCode < http.StatusInternalServerError {
+						return rsp, nil
+					}
+					body, readErr := io.ReadAll(rsp.Body)
+					rsp.Body.Close()
+					rsp.Body = io.NopCloser(bytes.NewReader(body))
+					if readErr != nil {
+						return rsp, nil
+					}
+					if after := retryAfter(rsp, body); after > wait {
+						wait = after
+					}
+				}
+				if cfg.MaxDelay > 0 && wait > cfg.MaxDelay {
+					return rsp, err
+				}
+				if req.Body != nil {
+					if req.GetBody == nil {
+						return rsp, err
+					}
+					body, bodyErr := req.GetBody()
+					if bodyErr != nil {
+						return rsp, err
+					}
+					req = req.Clone(req.Context())
+					req.Body = body
+				}
+				if rsp != nil {
+					rsp.Body.Close()
+				}
+				logging.S(req.Context()).Debugf("Retry %s in %s, attempt %d", path.Base(req.URL.Path), wait, attempt+1)
+				timer := time.NewTimer(wait)
+				select {
+				case <-timer.C:
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, xerrors.Errorf("retry: %w", req.Context().Err())
+				}
+				delay *= 2
+				if cfg.MaxDelay > 0 && delay > cfg.MaxDelay {
+					delay = cfg.MaxDelay
+				}
+			}
+		}
+	}
+}
+
+// ======== Fault injection ========
+
+type FaultConfig struct {
+	ErrorRate float64       `yaml:"error_rate"` // share of requests failed without sending
+	Status    int           `yaml:"status"`     // status of failed requests, network error if 0
+	Delay     time.Duration `yaml:"delay"`      // added to every request
+}
+
+// InjectedError is returned by Faults middleware instead of network error
+var InjectedError = xerrors.New("injected fault")
+
+func Faults(cfg FaultConfig) Middleware {
+	return func(next Doer) Doer {
+		return func(req *http.Request) (*http.Response, error) {
+			if cfg.Delay > 0 {
+				timer := time.NewTimer(cfg.Delay)
+				select {
+				case <-timer.C:
+				case <-req.Context().Done():
+					timer.Stop()
+					return nil, req.Context().Err()
+				}
+			}
+			if rand.Float64() >= cfg.ErrorRate {
+				return next(req)
+			}
+			if cfg.Status == 0 {
+				return nil, InjectedError
+			}
+			body := fmt.Sprintf(`{"ok":false,"error_code":%d,"description":%q}`,
+				cfg.Status, http.StatusText(cfg.Status))
+			return &http.Response{
+				Status:     strconv.Itoa(cfg.Status) + " " + http.StatusText(cfg.Status),
+				StatusCode: cfg.Status,
+				Header:     http.Header{"Content-Type": {"application/json"}},
+				Body:       io.NopCloser(bytes.NewBufferString(body)),
+				Request:    req,
+			}, nil
+		}
+	}
+}
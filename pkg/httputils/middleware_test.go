@@ -0,0 +1,127 @@
+package httputils
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestRetry(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		statuses []int // server responses, in order
+		config   RetryConfig
+		calls    int
+		err      bool
+	}{
+		{
+			desc:     "ok",
+			statuses: []int{http.StatusOK},
+			config:   RetryConfig{Attempts: 3, MinDelay: time.Millisecond},
+			calls:    1,
+		},
+		{
+			desc:     "retried",
+			statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK},
+			config:   RetryConfig{Attempts: 3, MinDelay: time.Millisecond},
+			calls:    3,
+		},
+		{
+			desc:     "out of attempts",
+			statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusOK},
+			config:   RetryConfig{Attempts: 2, MinDelay: time.Millisecond},
+			calls:    2,
+			err:      true,
+		},
+		{
+			desc:     "not retriable",
+			statuses: []int{http.StatusBadRequest, http.StatusOK},
+			config:   RetryConfig{Attempts: 3, MinDelay: time.Millisecond},
+			calls:    1,
+			err:      true,
+		},
+		{
+			desc:     "wait too long",
+			statuses: []int{http.StatusBadGateway, http.StatusOK},
+			config:   RetryConfig{Attempts: 3, MinDelay: time.Second, MaxDelay: time.Millisecond},
+			calls:    1,
+			err:      true,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			var calls int
+			server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+				var body map[string]string
+				assert.NoError(jsonBody(r, &body))
+				assert.Equal("value", body["field"])
+				rw.WriteHeader(c.statuses[calls])
+				rw.Write([]byte("{}"))
+				calls++
+			}))
+			defer server.Close()
+
+			client := BaseClient{
+				Client:      server.Client(),
+				Path:        server.URL + "/",
+				Middlewares: []Middleware{Retry(c.config, nil)},
+			}
+			err := client.Request(context.Background(), http.MethodPost, "method",
+				map[string]string{"field": "value"}, nil)
+			if c.err {
+				assert.Error(err)
+			} else {
+				assert.NoError(err)
+			}
+			assert.Equal(c.calls, calls)
+		})
+	}
+}
+
+func TestFaults(t *testing.T) {
+	assert := require.New(t)
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		calls++
+		rw.Write([]byte("{}"))
+	}))
+	defer server.Close()
+
+	var observed []int
+	client := BaseClient{
+		Client: server.Client(),
+		Path:   server.URL + "/",
+		Middlewares: []Middleware{
+			Metrics(func(method string, status int, duration time.Duration, err error) {
+				assert.Equal("method", method)
+				observed = append(observed, status)
+			}),
+			Faults(FaultConfig{ErrorRate: 1}),
+		},
+	}
+	err := client.Request(context.Background(), http.MethodPost, "method", nil, nil)
+	assert.True(xerrors.Is(err, InjectedError))
+
+	client.Middlewares[1] = Faults(FaultConfig{ErrorRate: 1, Status: http.StatusTooManyRequests})
+	err = client.Request(context.Background(), http.MethodPost, "method", nil, nil)
+	var status *StatusError
+	assert.True(xerrors.As(err, &status))
+	assert.Equal(http.StatusTooManyRequests, status.Code)
+
+	client.Middlewares[1] = Faults(FaultConfig{})
+	assert.NoError(client.Request(context.Background(), http.MethodPost, "method", nil, nil))
+
+	assert.Equal(1, calls)
+	assert.Equal([]int{0, http.StatusTooManyRequests, http.StatusOK}, observed)
+}
+
+func jsonBody(r *http.Request, v interface{}) error {
+	return json.NewDecoder(r.Body).Decode(v)
+}
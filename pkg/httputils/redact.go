@@ -0,0 +1,105 @@
+package httputils
+
+import (
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"golang.org/x/xerrors"
+)
+
+const redacted = "***"
+
+type RedactConfig struct {
+	// names of JSON fields hidden in request and response logs
+	Fields []string `yaml:"fields"`
+}
+
+// Redactor hides secrets and sensitive fields from logs and errors
+type Redactor struct {
+	secrets []string
+	fields  map[string]struct{}
+}
+
+func NewRedactor(cfg RedactConfig, secrets ...string) *Redactor {
+	r := &Redactor{fields: map[string]struct{}{}}
+	for _, secret := range secrets {
+		if secret != "" {
+			r.secrets = append(r.secrets, secret)
+		}
+	}
+	for _, field := range cfg.Fields {
+		r.fields[field] = struct{}{}
+	}
+	return r
+}
+
+// String masks secrets, e.g. in URLs
+func (r *Redactor) String(s string) string {
+	if r == nil {
+		return s
+	}
+	for _, secret := range r.secrets {
+		s = strings.ReplaceAll(s, secret, redacted)
+	}
+	return s
+}
+
+// Error masks secrets in URL of failed request
+func (r *Redactor) Error(err error) error {
+	var urlErr *url.Error
+	if r != nil && xerrors.As(err, &urlErr) {
+		urlErr.URL = r.String(urlErr.URL)
+	}
+	return err
+}
+
+// Body masks secrets and sensitive fields in JSON body
+func (r *Redactor) Body(body []byte) string {
+	if r == nil {
+		return string(body)
+	}
+	if len(r.fields) != 0 {
+		var value interface{}
+		if err := json.Unmarshal(body, &value); err == nil {
+			if b, err := json.Marshal(r.value(value)); err == nil {
+				body = b
+			}
+		}
+	}
+	return r.String(string(body))
+}
+
+// Fields masks secrets and sensitive fields in form fields
+func (r *Redactor) Fields(fields map[string]string) map[string]string {
+	if r == nil {
+		return fields
+	}
+	res := map[string]string{}
+	for name, value := range fields {
+		if _, ok := r.fields[name]; ok {
+			res[name] = redacted
+		} else {
+			res[name] = r.Body([]byte(value))
+		}
+	}
+	return res
+}
+
+func (r *Redactor) value(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for name, field := range v {
+			if _, ok := r.fields[name]; ok {
+				v[name] = redacted
+			} else {
+				v[name] = r.value(field)
+			}
+		}
+	case []interface{}:
+		for i, item := range v {
+			v[i] = r.value(item)
+		}
+	}
+	return value
+}
@@ -0,0 +1,89 @@
+package httputils
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestRedactor(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		fields []string
+		input  string
+		exp    string
+	}{
+		{
+			desc:  "url",
+			input: "https://api.telegram.org/bot123:secret/sendMessage",
+			exp:   "https://api.telegram.org/bot***/sendMessage",
+		},
+		{
+			desc:  "no fields",
+			input: `{"chat_id":1,"text":"hello"}`,
+			exp:   `{"chat_id":1,"text":"hello"}`,
+		},
+		{
+			desc:   "fields",
+			fields: []string{"text", "first_name"},
+			input:  `{"chat_id":1,"text":"hello"}`,
+			exp:    `{"chat_id":1,"text":"***"}`,
+		},
+		{
+			desc:   "nested",
+			fields: []string{"text", "first_name"},
+			input:  `{"ok":true,"result":[{"message":{"from":{"id":1,"first_name":"John"},"text":"hello"}}]}`,
+			exp:    `{"ok":true,"result":[{"message":{"from":{"first_name":"***","id":1},"text":"***"}}]}`,
+		},
+		{
+			desc:   "not json",
+			fields: []string{"text"},
+			input:  "bad gateway 123:secret",
+			exp:    "bad gateway ***",
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			r := NewRedactor(RedactConfig{Fields: c.fields}, "123:secret")
+			require.Equal(t, c.exp, r.Body([]byte(c.input)))
+		})
+	}
+}
+
+func TestRedactorError(t *testing.T) {
+	r := NewRedactor(RedactConfig{}, "123:secret")
+	err := xerrors.Errorf("request: %w", &url.Error{
+		Op:  "Post",
+		URL: "https://api.telegram.org/bot123:secret/sendMessage",
+		Err: xerrors.New("connection refused"),
+	})
+	err = r.Error(err)
+	require.NotContains(t, err.Error(), "secret")
+	require.NotContains(t, fmt.Sprintf("%#v", err), "secret")
+
+	var nilRedactor *Redactor
+	require.Equal(t, "bot123:secret", nilRedactor.String("bot123:secret"))
+}
+
+func TestRequestErrorRedacted(t *testing.T) {
+	// control character makes url unparsable, error quotes it
+	client := &BaseClient{
+		Client:   http.DefaultClient,
+		Path:     "https://api.telegram.org/bot123:secret\x7f/",
+		Redactor: NewRedactor(RedactConfig{}, "123:secret"),
+	}
+	err := client.Request(context.Background(), http.MethodPost, "getMe", nil, nil)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secret")
+	err = client.RequestForm(context.Background(), http.MethodPost, "sendDocument", nil, nil, nil)
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secret")
+	_, err = client.Get(context.Background(), client.Path+"file")
+	require.Error(t, err)
+	require.NotContains(t, err.Error(), "secret")
+}
@@ -0,0 +1,115 @@
+package httputils
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/url"
+	"os"
+
+	"golang.org/x/xerrors"
+)
+
+// ProxyConfig routes requests through proxy, direct if URL is empty
+type ProxyConfig struct {
+	// http://host:port for HTTP CONNECT, socks5://host:port for SOCKS5,
+	// socks5h:// resolves names at proxy
+	URL string `yaml:"url" env:"TGBOT_PROXY_URL"`
+	// override credentials of URL, if any
+	Username string `yaml:"username" env:"TGBOT_PROXY_USERNAME"`
+	Password string `yaml:"-" env:"TGBOT_PROXY_PASSWORD"`
+}
+
+// TLSConfig adds trusted CAs and client certificate, PEM files
+type TLSConfig struct {
+	// added to system roots
+	CAFile string `yaml:"ca_file"`
+	// mutual TLS if both are set
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+}
+
+type TransportConfig struct {
+	Proxy ProxyConfig `yaml:"proxy"`
+	TLS   TLSConfig   `yaml:"tls"`
+}
+
+var proxySchemes = map[string]bool{"http": true, "https": true, "socks5": true, "socks5h": true}
+
+// Transport is clone of default transport with proxy and TLS from config
+func (cfg TransportConfig) Transport() (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	proxy, err := cfg.Proxy.url()
+	if err != nil {
+		return nil, xerrors.Errorf("proxy: %w", err)
+	}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if transport.TLSClientConfig, err = cfg.TLS.config(); err != nil {
+		return nil, xerrors.Errorf("tls: %w", err)
+	}
+	return transport, nil
+}
+
+// Redacted hides passwords for logging
+func (cfg ProxyConfig) Redacted() ProxyConfig {
+	if cfg.Password != "" {
+		cfg.Password = redacted
+	}
+	if u, err := url.Parse(cfg.URL); err == nil {
+		cfg.URL = u.Redacted()
+	}
+	return cfg
+}
+
+func (cfg ProxyConfig) url() (*url.URL, error) {
+	if cfg.URL == "" {
+		return nil, nil
+	}
+	res, err := url.Parse(cfg.URL)
+	if err != nil {
+		return nil, xerrors.Errorf("parse: %w", err)
+	}
+	if !proxySchemes[res.Scheme] || res.Host == "" {
+		return nil, xerrors.Errorf("unsupported proxy %q", res.Redacted())
+	}
+	if cfg.Username != "" || cfg.Password != "" {
+		// config overrides each of URL credentials
+		username, password := cfg.Username, cfg.Password
+		if res.User != nil {
+			if username == "" {
+				username = res.User.Username()
+			}
+			if password == "" {
+				password, _ = res.User.Password()
+			}
+		}
+		res.User = url.UserPassword(username, password)
+	}
+	return res, nil
+}
+
+func (cfg TLSConfig) config() (*tls.Config, error) {
+	res := &tls.Config{}
+	if cfg.CAFile != "" {
+		pem, err := os.ReadFile(cfg.CAFile)
+		if err != nil {
+			return nil, xerrors.Errorf("read ca: %w", err)
+		}
+		if res.RootCAs, err = x509.SystemCertPool(); err != nil {
+			res.RootCAs = x509.NewCertPool()
+		}
+		if !res.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, xerrors.Errorf("no certificates in %s", cfg.CAFile)
+		}
+	}
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, xerrors.Errorf("load client cert: %w", err)
+		}
+		res.Certificates = []tls.Certificate{cert}
+	}
+	return res, nil
+}
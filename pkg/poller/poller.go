@@ -14,6 +14,9 @@ import (
 	"github.com/baldisbk/tgbot/pkg/tgapi"
 )
 
+// delay after long poll error if PollPeriod is less, so failing API is not hammered
+const minErrorDelay = time.Second
+
 type Poller struct {
 	Client tgapi.TGClient
 	Engine engine.Engine
@@ -21,15 +24,33 @@ type Poller struct {
 	config Config
 	clock  clockwork.Clock
 	offset uint64
+	// no polls until then, as requested by API
+	pause time.Time
 
 	stopper chan struct{}
 }
 
 type Config struct {
 	PollPeriod time.Duration `yaml:"period"`
+	// long polling timeout, if set polls are continuous
+	// and PollPeriod is only a delay after errors
+	Timeout        time.Duration `yaml:"timeout"`
+	Limit          int           `yaml:"limit"`
+	AllowedUpdates []string      `yaml:"allowed_updates"`
+}
+
+// timeout is long polling timeout in seconds, rounded up so that
+// sub-second one is still long polling, not busy loop
+func (c Config) timeout() int {
+	return int((c.Timeout + time.Second - 1) / time.Second)
 }
 
 func NewPoller(ctx context.Context, cfg Config, client tgapi.TGClient, engine engine.Engine) *Poller {
+	// getUpdates fails with 409 Conflict while webhook is set,
+	// e.g. left by previous run in webhook mode
+	if _, err := tgapi.DeleteWebhook(ctx, client, tgapi.DeleteWebhookParams{}); err != nil {
+		logging.S(ctx).Errorf("Delete webhook: %#v", err)
+	}
 	return newPoller(ctx, cfg, clockwork.NewRealClock(), client, engine)
 }
 
@@ -51,11 +72,28 @@ func (p *Poller) Shutdown() { p.stopper <- struct{}{} }
 func (p *Poller) Sync(ctx context.Context) error { return p.do(ctx, true) }
 
 func (p *Poller) do(ctx context.Context, inSync bool) error {
-	upds, offset, err := p.Client.GetUpdates(ctx, p.offset)
+	upds, err := p.fetch(ctx)
 	if err != nil {
 		return xerrors.Errorf("get updates: %w", err)
 	}
+	return p.dispatch(ctx, upds, inSync)
+}
+
+func (p *Poller) fetch(ctx context.Context) ([]tgapi.Update, error) {
+	upds, offset, err := p.Client.GetUpdates(ctx, tgapi.GetUpdates{
+		Offset:         p.offset,
+		Limit:          p.config.Limit,
+		Timeout:        p.config.timeout(),
+		AllowedUpdates: p.config.AllowedUpdates,
+	})
+	if err != nil {
+		return nil, err
+	}
 	p.offset = offset
+	return upds, nil
+}
+
+func (p *Poller) dispatch(ctx context.Context, upds []tgapi.Update, inSync bool) error {
 	errors := make(chan error, len(upds))
 	var wg sync.WaitGroup
 	if inSync {
@@ -67,19 +105,7 @@ func (p *Poller) do(ctx context.Context, inSync bool) error {
 	}
 	for _, upd := range upds {
 		go func(upd tgapi.Update) {
-			var err error
-			switch {
-			case upd.Message != nil:
-				ctx = logging.WithTag(ctx, "EVENT", upd.Message.UUID)
-				if err = p.Engine.Receive(ctx, upd.Message); err != nil {
-					err = xerrors.Errorf("receive message (%#v): %w", upd.Message, err)
-				}
-			case upd.CallbackQuery != nil:
-				ctx = logging.WithTag(ctx, "EVENT", upd.CallbackQuery.UUID)
-				if err = p.Engine.Receive(ctx, upd.CallbackQuery); err != nil {
-					err = xerrors.Errorf("receive callback (%#v): %w", upd.CallbackQuery, err)
-				}
-			}
+			err := engine.Dispatch(ctx, p.Engine, upd)
 			if err != nil {
 				logging.S(ctx).Errorf("Error processing update: %#v", err)
 			}
@@ -102,12 +128,20 @@ func (p *Poller) do(ctx context.Context, inSync bool) error {
 }
 
 func (p *Poller) run(ctx context.Context) {
+	if p.config.Timeout > 0 {
+		p.runLong(ctx)
+		return
+	}
 	ticker := p.clock.NewTicker(p.config.PollPeriod)
 	for {
 		select {
 		case <-ticker.Chan():
+			if p.clock.Now().Before(p.pause) {
+				continue
+			}
 			if err := p.do(ctx, false); err != nil {
 				logging.S(ctx).Errorf("Error processing updates: %#v", err)
+				p.pause = p.clock.Now().Add(retryAfter(err))
 			}
 		case <-p.stopper:
 			return
@@ -116,3 +150,48 @@ func (p *Poller) run(ctx context.Context) {
 		}
 	}
 }
+
+func (p *Poller) runLong(ctx context.Context) {
+	// cancel pending poll on shutdown, but not processing
+	pollCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	go func() {
+		select {
+		case <-p.stopper:
+			cancel()
+		case <-pollCtx.Done():
+		}
+	}()
+	for {
+		upds, err := p.fetch(pollCtx)
+		if pollCtx.Err() != nil {
+			return
+		}
+		if err != nil {
+			logging.S(ctx).Errorf("Error processing updates: %#v", err)
+			delay := p.config.PollPeriod
+			if delay < minErrorDelay {
+				delay = minErrorDelay
+			}
+			if after := retryAfter(err); after > delay {
+				delay = after
+			}
+			select {
+			case <-p.clock.After(delay):
+			case <-pollCtx.Done():
+				return
+			}
+			continue
+		}
+		p.dispatch(ctx, upds, false)
+	}
+}
+
+// retryAfter returns delay requested by API, if any
+func retryAfter(err error) time.Duration {
+	var apiErr *tgapi.Error
+	if xerrors.As(err, &apiErr) {
+		return apiErr.RetryAfter
+	}
+	return 0
+}
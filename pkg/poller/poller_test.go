@@ -9,6 +9,7 @@ import (
 	"github.com/baldisbk/tgbot/pkg/tgapi"
 	"github.com/jonboulle/clockwork"
 	"github.com/stretchr/testify/mock"
+	"golang.org/x/xerrors"
 )
 
 func TestPoller(t *testing.T) {
@@ -28,6 +29,42 @@ func TestPoller(t *testing.T) {
 				CallbackQuery: &tgapi.CallbackQuery{Data: "data"},
 			},
 		},
+		{
+			desc: "inline",
+			update: tgapi.Update{
+				InlineQuery: &tgapi.InlineQuery{Query: "query"},
+			},
+		},
+		{
+			desc: "chosen inline",
+			update: tgapi.Update{
+				ChosenInlineResult: &tgapi.ChosenInlineResult{ResultId: "result"},
+			},
+		},
+		{
+			desc: "edited",
+			update: tgapi.Update{
+				EditedMessage: &tgapi.EditedMessage{Text: "edited"},
+			},
+		},
+		{
+			desc: "blocked",
+			update: tgapi.Update{
+				MyChatMember: &tgapi.ChatMemberUpdated{NewChatMember: tgapi.ChatMember{Status: tgapi.MemberKicked}},
+			},
+		},
+		{
+			desc: "poll answer",
+			update: tgapi.Update{
+				PollAnswer: &tgapi.PollAnswer{PollId: "poll", OptionIds: []int{1}},
+			},
+		},
+		{
+			desc: "poll",
+			update: tgapi.Update{
+				Poll: &tgapi.Poll{Id: "poll"},
+			},
+		},
 	}
 	for _, tC := range testCases {
 		t.Run(tC.desc, func(t *testing.T) {
@@ -69,6 +106,43 @@ func TestPoller(t *testing.T) {
 					mock.MatchedBy(func(e *tgapi.CallbackQuery) bool { return e.Data == "data" }),
 				).Return(nil).Once()
 			}
+			if tC.update.InlineQuery != nil {
+				engine.On(
+					"Receive",
+					mock.Anything,
+					mock.MatchedBy(func(e *tgapi.InlineQuery) bool { return e.Query == "query" }),
+				).Return(nil).Once()
+			}
+			if tC.update.ChosenInlineResult != nil {
+				engine.On(
+					"Receive",
+					mock.Anything,
+					mock.MatchedBy(func(e *tgapi.ChosenInlineResult) bool { return e.ResultId == "result" }),
+				).Return(nil).Once()
+			}
+			if tC.update.EditedMessage != nil {
+				engine.On(
+					"Receive",
+					mock.Anything,
+					mock.MatchedBy(func(e *tgapi.EditedMessage) bool { return e.Text == "edited" }),
+				).Return(nil).Once()
+			}
+			if tC.update.MyChatMember != nil {
+				engine.On(
+					"Receive",
+					mock.Anything,
+					mock.MatchedBy(func(e *tgapi.ChatMemberUpdated) bool {
+						return e.NewChatMember.Status == tgapi.MemberKicked
+					}),
+				).Return(nil).Once()
+			}
+			if tC.update.PollAnswer != nil {
+				engine.On(
+					"Receive",
+					mock.Anything,
+					mock.MatchedBy(func(e *tgapi.PollAnswer) bool { return e.PollId == "poll" }),
+				).Return(nil).Once()
+			}
 			clock.Advance(time.Second)
 			time.Sleep(time.Millisecond)
 			// engine called
@@ -76,3 +150,135 @@ func TestPoller(t *testing.T) {
 		})
 	}
 }
+
+func TestLongPoller(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := clockwork.NewFakeClock()
+	engine := engine.NewEngineMock()
+	tgClient := tgapi.NewMock()
+
+	cfg := Config{PollPeriod: time.Second, Timeout: 30 * time.Second, Limit: 10}
+	received := make(chan struct{})
+	engine.On(
+		"Receive",
+		mock.Anything,
+		mock.MatchedBy(func(e *tgapi.Message) bool { return e.Text == "text" }),
+	).Run(func(mock.Arguments) { close(received) }).Return(nil).Once()
+	tgClient.On(
+		"GetUpdates",
+		mock.Anything,
+		tgapi.GetUpdates{Offset: 0, Limit: 10, Timeout: 30},
+	).Return([]tgapi.Update{{UpdateId: 5, Message: &tgapi.Message{Text: "text"}}}, uint64(6), nil).Once()
+	// next poll hangs until shutdown
+	tgClient.On(
+		"GetUpdates",
+		mock.Anything,
+		tgapi.GetUpdates{Offset: 6, Limit: 10, Timeout: 30},
+	).Run(func(args mock.Arguments) {
+		<-args[0].(context.Context).Done()
+	}).Return([]tgapi.Update{}, uint64(6), context.Canceled).Once()
+
+	// no ticks needed
+	poller := newPoller(ctx, cfg, clock, tgClient, engine)
+	select {
+	case <-received:
+	case <-time.After(time.Second):
+		t.Fatal("update not received")
+	}
+	poller.Shutdown()
+	engine.AssertExpectations(t)
+}
+
+func TestLongPollerErrorDelay(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := clockwork.NewFakeClock()
+	engine := engine.NewEngineMock()
+	tgClient := tgapi.NewMock()
+
+	// no PollPeriod, still must not retry at once
+	cfg := Config{Timeout: 30 * time.Second}
+	polled := make(chan struct{})
+	tgClient.On(
+		"GetUpdates",
+		mock.Anything,
+		tgapi.GetUpdates{Offset: 0, Timeout: 30},
+	).Return([]tgapi.Update{}, uint64(0), xerrors.New("network")).Once()
+	tgClient.On(
+		"GetUpdates",
+		mock.Anything,
+		tgapi.GetUpdates{Offset: 0, Timeout: 30},
+	).Run(func(args mock.Arguments) {
+		close(polled)
+		<-args[0].(context.Context).Done()
+	}).Return([]tgapi.Update{}, uint64(0), context.Canceled).Once()
+
+	poller := newPoller(ctx, cfg, clock, tgClient, engine)
+	clock.BlockUntil(1)
+	select {
+	case <-polled:
+		t.Fatal("retried without delay")
+	case <-time.After(50 * time.Millisecond):
+	}
+	clock.Advance(minErrorDelay)
+	select {
+	case <-polled:
+	case <-time.After(time.Second):
+		t.Fatal("not retried")
+	}
+	poller.Shutdown()
+	tgClient.AssertExpectations(t)
+}
+
+func TestLongPollerSubsecond(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	clock := clockwork.NewFakeClock()
+	engine := engine.NewEngineMock()
+	tgClient := tgapi.NewMock()
+
+	// 500ms is not 0 for API
+	cfg := Config{Timeout: 500 * time.Millisecond}
+	polled := make(chan struct{})
+	tgClient.On(
+		"GetUpdates",
+		mock.Anything,
+		tgapi.GetUpdates{Offset: 0, Timeout: 1},
+	).Run(func(args mock.Arguments) {
+		close(polled)
+		<-args[0].(context.Context).Done()
+	}).Return([]tgapi.Update{}, uint64(0), context.Canceled).Once()
+
+	poller := newPoller(ctx, cfg, clock, tgClient, engine)
+	select {
+	case <-polled:
+	case <-time.After(time.Second):
+		t.Fatal("not polled")
+	}
+	poller.Shutdown()
+	tgClient.AssertExpectations(t)
+}
+
+func TestPollerDeletesWebhook(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	engine := engine.NewEngineMock()
+	tgClient := tgapi.NewMock()
+	tgClient.On(
+		"Call",
+		mock.Anything,
+		tgapi.DeleteWebhookCmd,
+		tgapi.DeleteWebhookParams{},
+		mock.Anything,
+	).Return(nil).Once()
+
+	// no polls within an hour
+	poller := NewPoller(ctx, Config{PollPeriod: time.Hour}, tgClient, engine)
+	poller.Shutdown()
+	tgClient.AssertExpectations(t)
+}
@@ -0,0 +1,104 @@
+package tgapi
+
+//go:generate go run github.com/baldisbk/tgbot/cmd/tgapigen -spec spec/botapi.json -out generated.go
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/baldisbk/tgbot/pkg/httputils"
+
+	"golang.org/x/xerrors"
+)
+
+// Caller calls any Bot API method, see generated.go for params and results
+type Caller interface {
+	Call(ctx context.Context, method string, params interface{}, result interface{}) error
+}
+
+// Response is envelope of every Bot API response
+type Response struct {
+	Ok          bool            `json:"ok"`
+	Result      json.RawMessage `json:"result,omitempty"`
+	Description string          `json:"description,omitempty"`
+}
+
+// decode puts result into output, if any
+func (r Response) decode(method string, output interface{}) error {
+	if !r.Ok {
+		return xerrors.Errorf("%s: unexpected response: %s", method, r.Description)
+	}
+	if output == nil {
+		return nil
+	}
+	if err := json.Unmarshal(r.Result, output); err != nil {
+		return xerrors.Errorf("%s: parse result: %w", method, err)
+	}
+	return nil
+}
+
+// Call sends params as JSON and decodes result, errors are same as of typed methods;
+// messages to chats are queued by rate limiter as typed ones
+func (c *tgClient) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if limited(method) {
+		if err := c.limiter.wait(ctx, chatOf(params)); err != nil {
+			return xerrors.Errorf("rate limit: %w", err)
+		}
+	}
+	return c.call(ctx, method, params, result)
+}
+
+// call is Call with no rate limit
+func (c *tgClient) call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	if params == nil {
+		params = struct{}{}
+	}
+	var rsp Response
+	if err := c.Request(ctx, http.MethodPost, method, params, &rsp); err != nil {
+		return err
+	}
+	return rsp.decode(method, result)
+}
+
+// prefixes of methods posting or changing messages in chat
+var limitedPrefixes = []string{"send", "copy", "forward", "edit", "stop"}
+
+func limited(method string) bool {
+	if method == SendChatActionCmd {
+		// not worth waiting for
+		return false
+	}
+	for _, prefix := range limitedPrefixes {
+		if strings.HasPrefix(method, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// chatOf is chat_id of params, 0 for @channelusername or none
+func chatOf(params interface{}) int64 {
+	body, err := json.Marshal(params)
+	if err != nil {
+		return 0
+	}
+	var chat struct {
+		ChatId json.Number `json:"chat_id"`
+	}
+	if err := json.Unmarshal(body, &chat); err != nil {
+		return 0
+	}
+	res, _ := chat.ChatId.Int64()
+	return res
+}
+
+// callForm is Call with files sent as multipart form
+func (c *tgClient) callForm(ctx context.Context, method string, params interface{}, files map[string]httputils.FormFile, result interface{}) error {
+	var rsp Response
+	if err := c.RequestForm(ctx, http.MethodPost, method, params, files, &rsp); err != nil {
+		return err
+	}
+	return rsp.decode(method, result)
+}
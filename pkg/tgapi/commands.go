@@ -0,0 +1,131 @@
+package tgapi
+
+import (
+	"context"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/baldisbk/tgbot/pkg/statemachine"
+)
+
+// ======== Command registration ========
+
+type BotCommand struct {
+	Command     string `json:"command"` // without slash
+	Description string `json:"description"`
+}
+
+// scope types
+const (
+	DefaultScope               = "default"
+	AllPrivateChatsScope       = "all_private_chats"
+	AllGroupChatsScope         = "all_group_chats"
+	AllChatAdministratorsScope = "all_chat_administrators"
+	ChatScope                  = "chat"
+	ChatAdministratorsScope    = "chat_administrators"
+	ChatMemberScope            = "chat_member"
+)
+
+// BotCommandScope limits commands to chats or users, nil is default scope
+type BotCommandScope struct {
+	Type   string `json:"type"`
+	ChatId int64  `json:"chat_id,omitempty"` // chat, chat_administrators, chat_member
+	UserId uint64 `json:"user_id,omitempty"` // chat_member
+}
+
+type SetMyCommands struct {
+	Commands     []BotCommand     `json:"commands"`
+	Scope        *BotCommandScope `json:"scope,omitempty"`
+	LanguageCode string           `json:"language_code,omitempty"`
+}
+
+// for get and delete
+type MyCommands struct {
+	Scope        *BotCommandScope `json:"scope,omitempty"`
+	LanguageCode string           `json:"language_code,omitempty"`
+}
+
+// ======== Command parsing ========
+
+const botCommandEntity = "bot_command"
+
+// Command is parsed "/name@mention args" message
+type Command struct {
+	Name    string // without slash
+	Mention string // bot username, if addressed explicitly
+	Args    string
+}
+
+// Command parses message starting with bot command
+func (m *Message) Command() (Command, bool) {
+	text := m.Text
+	if len(m.Entities) != 0 {
+		// trust entities when they are present
+		entity := m.Entities[0]
+		if entity.Type != botCommandEntity || entity.Offset != 0 {
+			return Command{}, false
+		}
+		units := utf16.Encode([]rune(text))
+		if entity.Length > len(units) {
+			return Command{}, false
+		}
+		cmd := string(utf16.Decode(units[:entity.Length]))
+		return parseCommand(cmd, text[len(cmd):])
+	}
+	if !strings.HasPrefix(text, "/") {
+		return Command{}, false
+	}
+	cmd := text
+	if i := strings.IndexAny(text, " \t\n"); i >= 0 {
+		cmd = text[:i]
+	}
+	return parseCommand(cmd, text[len(cmd):])
+}
+
+func parseCommand(cmd, args string) (Command, bool) {
+	cmd = strings.TrimPrefix(cmd, "/")
+	if cmd == "" {
+		return Command{}, false
+	}
+	res := Command{Args: strings.TrimSpace(args)}
+	if i := strings.Index(cmd, "@"); i >= 0 {
+		res.Name, res.Mention = cmd[:i], cmd[i+1:]
+	} else {
+		res.Name = cmd
+	}
+	return res, res.Name != ""
+}
+
+// AddressedTo is true if command has no mention or mentions the bot
+func (c Command) AddressedTo(username string) bool {
+	return c.Mention == "" || strings.EqualFold(c.Mention, username)
+}
+
+// IsCommand matches any of given commands, regardless of mention
+func IsCommand(names ...string) statemachine.SMPredicate {
+	return isCommand(func(Command) bool { return true }, names)
+}
+
+// IsCommandTo matches any of given commands not addressed to other bots in groups
+func IsCommandTo(username string, names ...string) statemachine.SMPredicate {
+	return isCommand(func(cmd Command) bool { return cmd.AddressedTo(username) }, names)
+}
+
+func isCommand(check func(Command) bool, names []string) statemachine.SMPredicate {
+	return func(ctx context.Context, state string, input interface{}) bool {
+		msg, ok := input.(*Message)
+		if !ok || msg == nil {
+			return false
+		}
+		cmd, ok := msg.Command()
+		if !ok || !check(cmd) {
+			return false
+		}
+		for _, name := range names {
+			if strings.EqualFold(cmd.Name, name) {
+				return true
+			}
+		}
+		return false
+	}
+}
@@ -0,0 +1,100 @@
+package tgapi
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommand(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		message Message
+		command Command
+		ok      bool
+		other   bool // addressed to other bot
+	}{
+		{
+			desc:    "plain",
+			message: Message{Text: "/start"},
+			command: Command{Name: "start"},
+			ok:      true,
+		},
+		{
+			desc:    "mention and args",
+			message: Message{Text: "/start@OurBot  ref_42 "},
+			command: Command{Name: "start", Mention: "OurBot", Args: "ref_42"},
+			ok:      true,
+		},
+		{
+			desc: "entity",
+			message: Message{
+				Text:     "/start@OurBot payload",
+				Entities: []MessageEntity{{Type: botCommandEntity, Offset: 0, Length: 13}},
+			},
+			command: Command{Name: "start", Mention: "OurBot", Args: "payload"},
+			ok:      true,
+		},
+		{
+			desc: "entity not first",
+			message: Message{
+				Text:     "say /start",
+				Entities: []MessageEntity{{Type: botCommandEntity, Offset: 4, Length: 6}},
+			},
+		},
+		{
+			desc: "other entity",
+			message: Message{
+				Text:     "/start",
+				Entities: []MessageEntity{{Type: "bold", Offset: 0, Length: 6}},
+			},
+		},
+		{
+			desc:    "other bot",
+			message: Message{Text: "/start@OtherBot"},
+			command: Command{Name: "start", Mention: "OtherBot"},
+			ok:      true,
+			other:   true,
+		},
+		{
+			desc:    "text",
+			message: Message{Text: "start"},
+		},
+		{
+			desc:    "slash only",
+			message: Message{Text: "/ start"},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			command, ok := tC.message.Command()
+			require.Equal(t, tC.ok, ok)
+			require.Equal(t, tC.command, command)
+			require.Equal(t, tC.ok, IsCommand("help", "START")(context.Background(), "", &tC.message))
+			require.Equal(t, tC.ok && !tC.other, IsCommandTo("ourbot", "start")(context.Background(), "", &tC.message))
+			require.False(t, IsCommandTo("ourbot", "help")(context.Background(), "", &tC.message))
+		})
+	}
+}
+
+func TestSetMyCommands(t *testing.T) {
+	assert := require.New(t)
+	client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal("/bottoken/"+SetCommandsCmd, r.URL.Path)
+		var req map[string]interface{}
+		assert.NoError(json.NewDecoder(r.Body).Decode(&req))
+		assert.Equal(map[string]interface{}{
+			"commands":      []interface{}{map[string]interface{}{"command": "start", "description": "Start over"}},
+			"scope":         map[string]interface{}{"type": AllPrivateChatsScope},
+			"language_code": "en",
+		}, req)
+		rw.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	err := client.SetMyCommands(context.Background(),
+		[]BotCommand{{Command: "start", Description: "Start over"}},
+		&BotCommandScope{Type: AllPrivateChatsScope}, "en")
+	assert.NoError(err)
+}
@@ -0,0 +1,51 @@
+package tgapi
+
+import (
+	"encoding/base64"
+	"net/url"
+	"regexp"
+
+	"golang.org/x/xerrors"
+)
+
+// StartCommand is sent by client on first contact and on deep links
+const StartCommand = "start"
+
+// telegram allows up to 64 of A-Z, a-z, 0-9, _ and -
+var startParameterRe = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+var BadStartParameterError = xerrors.New("bad start parameter")
+
+// StartParameter is payload of t.me/bot?start=payload link, false if there is none
+func (m *Message) StartParameter() (string, bool) {
+	cmd, ok := m.Command()
+	if !ok || cmd.Name != StartCommand || !startParameterRe.MatchString(cmd.Args) {
+		return "", false
+	}
+	return cmd.Args, true
+}
+
+// DeepLink makes link opening private chat with bot with start payload
+func DeepLink(username, payload string) (string, error) {
+	if !startParameterRe.MatchString(payload) {
+		return "", xerrors.Errorf("%w: %q", BadStartParameterError, payload)
+	}
+	return "https://t.me/" + username + "?" + url.Values{"start": {payload}}.Encode(), nil
+}
+
+// EncodeStartParameter packs arbitrary data into start payload
+func EncodeStartParameter(data []byte) (string, error) {
+	res := base64.RawURLEncoding.EncodeToString(data)
+	if !startParameterRe.MatchString(res) {
+		return "", xerrors.Errorf("%w: %d bytes is too long", BadStartParameterError, len(data))
+	}
+	return res, nil
+}
+
+func DecodeStartParameter(payload string) ([]byte, error) {
+	res, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, xerrors.Errorf("%w: %s", BadStartParameterError, err)
+	}
+	return res, nil
+}
@@ -0,0 +1,57 @@
+package tgapi
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+)
+
+func TestStartParameter(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		text    string
+		payload string
+		ok      bool
+	}{
+		{desc: "bare start", text: "/start"},
+		{desc: "payload", text: "/start ref_campaign-1", payload: "ref_campaign-1", ok: true},
+		{desc: "mention", text: "/start@OurBot tpl_run", payload: "tpl_run", ok: true},
+		{desc: "other command", text: "/help ref_1"},
+		{desc: "bad symbols", text: "/start hello world"},
+		{desc: "too long", text: "/start " + strings.Repeat("a", 65)},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			msg := Message{Text: c.text}
+			payload, ok := msg.StartParameter()
+			assert.Equal(c.ok, ok)
+			assert.Equal(c.payload, payload)
+		})
+	}
+}
+
+func TestDeepLink(t *testing.T) {
+	assert := require.New(t)
+
+	link, err := DeepLink("OurBot", "ref_1")
+	assert.NoError(err)
+	assert.Equal("https://t.me/OurBot?start=ref_1", link)
+
+	_, err = DeepLink("OurBot", "ref 1")
+	assert.True(xerrors.Is(err, BadStartParameterError))
+
+	payload, err := EncodeStartParameter([]byte("achievement: run 10k"))
+	assert.NoError(err)
+	msg := Message{Text: "/start " + payload}
+	got, ok := msg.StartParameter()
+	assert.True(ok)
+	data, err := DecodeStartParameter(got)
+	assert.NoError(err)
+	assert.Equal("achievement: run 10k", string(data))
+
+	_, err = EncodeStartParameter(make([]byte, 49))
+	assert.True(xerrors.Is(err, BadStartParameterError))
+}
@@ -0,0 +1,120 @@
+package tgapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/baldisbk/tgbot/pkg/httputils"
+)
+
+// use with xerrors.Is on errors returned by client
+var (
+	BadRequestError      = xerrors.New("bad request")       // 400
+	UnauthorizedError    = xerrors.New("unauthorized")      // 401, bad token
+	ForbiddenError       = xerrors.New("forbidden")         // 403, e.g. bot was blocked
+	NotFoundError        = xerrors.New("not found")         // 404
+	ConflictError        = xerrors.New("conflict")          // 409, e.g. webhook is set
+	TooManyRequestsError = xerrors.New("too many requests") // 429, see RetryAfter
+	ServerError          = xerrors.New("server error")      // 5xx
+
+	NotModifiedError = xerrors.New("message is not modified") // 400 on edit with same contents
+	MigratedError    = xerrors.New("group migrated")          // 400, see MigrateToChatId
+
+	FileTooBigError   = xerrors.New("file too big")      // exceeds configured download limit
+	InvalidTokenError = xerrors.New("invalid bot token") // rejected by getMe
+)
+
+// Bot API error response
+type Error struct {
+	Code            int
+	Description     string
+	RetryAfter      time.Duration
+	MigrateToChatId int64
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("bot api error %d: %s", e.Code, e.Description)
+}
+
+func (e *Error) Is(target error) bool {
+	switch target {
+	case BadRequestError:
+		return e.Code == http.StatusBadRequest
+	case UnauthorizedError:
+		return e.Code == http.StatusUnauthorized
+	case ForbiddenError:
+		return e.Code == http.StatusForbidden
+	case NotFoundError:
+		return e.Code == http.StatusNotFound
+	case ConflictError:
+		return e.Code == http.StatusConflict
+	case TooManyRequestsError:
+		return e.Code == http.StatusTooManyRequests
+	case ServerError:
+		return e.Code >= http.StatusInternalServerError
+	case NotModifiedError:
+		return e.Code == http.StatusBadRequest && strings.Contains(e.Description, "message is not modified")
+	case MigratedError:
+		return e.MigrateToChatId != 0
+	}
+	return false
+}
+
+// worth repeating later
+func (e *Error) Retriable() bool {
+	return e.Code == http.StatusTooManyRequests || e.Code >= http.StatusInternalServerError
+}
+
+// no use repeating, ever
+func (e *Error) Fatal() bool {
+	switch e.Code {
+	case http.StatusUnauthorized, http.StatusForbidden, http.StatusNotFound:
+		return true
+	}
+	return false
+}
+
+type ResponseParameters struct {
+	MigrateToChatId int64 `json:"migrate_to_chat_id,omitempty"`
+	RetryAfter      int   `json:"retry_after,omitempty"`
+}
+
+type ErrorResponse struct {
+	Ok          bool                `json:"ok"`
+	ErrorCode   int                 `json:"error_code"`
+	Description string              `json:"description"`
+	Parameters  *ResponseParameters `json:"parameters,omitempty"`
+}
+
+// apiError converts http status errors to *Error
+func apiError(err error) error {
+	var status *httputils.StatusError
+	if err == nil || !xerrors.As(err, &status) {
+		return err
+	}
+	res := &Error{Code: status.Code, Description: http.StatusText(status.Code)}
+	var rsp ErrorResponse
+	if json.Unmarshal(status.Body, &rsp) == nil && rsp.ErrorCode != 0 {
+		res.Code = rsp.ErrorCode
+		res.Description = rsp.Description
+		if rsp.Parameters != nil {
+			res.RetryAfter = time.Duration(rsp.Parameters.RetryAfter) * time.Second
+			res.MigrateToChatId = rsp.Parameters.MigrateToChatId
+		}
+	}
+	return res
+}
+
+// retryAfter reads retry_after of Bot API error response for retry middleware
+func retryAfter(rsp *http.Response, body []byte) time.Duration {
+	var res ErrorResponse
+	if json.Unmarshal(body, &res) == nil && res.Parameters != nil {
+		return time.Duration(res.Parameters.RetryAfter) * time.Second
+	}
+	return httputils.HeaderRetryAfter(rsp, body)
+}
@@ -0,0 +1,104 @@
+package tgapi
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/baldisbk/tgbot/pkg/httputils"
+)
+
+func TestApiError(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		err    error
+		exp    *Error
+		expIs  []error
+		expNot []error
+	}{
+		{
+			desc: "flood",
+			err: &httputils.StatusError{
+				Code: http.StatusTooManyRequests,
+				Body: []byte(`{"ok":false,"error_code":429,"description":"Too Many Requests: retry after 5","parameters":{"retry_after":5}}`),
+			},
+			exp: &Error{
+				Code:        429,
+				Description: "Too Many Requests: retry after 5",
+				RetryAfter:  5 * time.Second,
+			},
+			expIs:  []error{TooManyRequestsError},
+			expNot: []error{BadRequestError, ForbiddenError},
+		},
+		{
+			desc: "blocked",
+			err: &httputils.StatusError{
+				Code: http.StatusForbidden,
+				Body: []byte(`{"ok":false,"error_code":403,"description":"Forbidden: bot was blocked by the user"}`),
+			},
+			exp: &Error{
+				Code:        403,
+				Description: "Forbidden: bot was blocked by the user",
+			},
+			expIs:  []error{ForbiddenError},
+			expNot: []error{TooManyRequestsError},
+		},
+		{
+			desc: "not modified",
+			err: xerrors.Errorf("wrapped: %w", &httputils.StatusError{
+				Code: http.StatusBadRequest,
+				Body: []byte(`{"ok":false,"error_code":400,"description":"Bad Request: message is not modified"}`),
+			}),
+			exp: &Error{
+				Code:        400,
+				Description: "Bad Request: message is not modified",
+			},
+			expIs:  []error{BadRequestError, NotModifiedError},
+			expNot: []error{MigratedError},
+		},
+		{
+			desc: "migrated",
+			err: &httputils.StatusError{
+				Code: http.StatusBadRequest,
+				Body: []byte(`{"ok":false,"error_code":400,"description":"Bad Request: group chat was upgraded to a supergroup chat","parameters":{"migrate_to_chat_id":-1001234567890}}`),
+			},
+			exp: &Error{
+				Code:            400,
+				Description:     "Bad Request: group chat was upgraded to a supergroup chat",
+				MigrateToChatId: -1001234567890,
+			},
+			expIs:  []error{BadRequestError, MigratedError},
+			expNot: []error{NotModifiedError},
+		},
+		{
+			desc: "no envelope",
+			err: &httputils.StatusError{
+				Code: http.StatusBadGateway,
+				Body: []byte(`<html>Bad Gateway</html>`),
+			},
+			exp: &Error{
+				Code:        502,
+				Description: "Bad Gateway",
+			},
+			expIs: []error{ServerError},
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			err := apiError(c.err)
+			var apiErr *Error
+			assert.True(xerrors.As(err, &apiErr))
+			assert.Equal(c.exp, apiErr)
+			for _, target := range c.expIs {
+				assert.True(xerrors.Is(err, target), "is %v", target)
+			}
+			for _, target := range c.expNot {
+				assert.False(xerrors.Is(err, target), "is not %v", target)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+// Code generated by tgapigen from spec/botapi.json; DO NOT EDIT.
+// Bot API 7.0
+
+package tgapi
+
+import "context"
+
+// ======== Types ========
+
+// animated emoji with random value
+type Dice struct {
+	Emoji string `json:"emoji"` // emoji the dice animation is based on
+	Value int    `json:"value"` // value of the dice, 1-6 or 1-64 for slot machine
+}
+
+// id of sent message
+type MessageId struct {
+	MessageId uint64 `json:"message_id"` // message identifier
+}
+
+// reaction with a standard emoji
+type ReactionTypeEmoji struct {
+	Type  string `json:"type"`  // always "emoji"
+	Emoji string `json:"emoji"` // one of allowed reaction emojis
+}
+
+// profile pictures of a user
+type UserProfilePhotos struct {
+	TotalCount int           `json:"total_count"` // total number of pictures
+	Photos     [][]PhotoSize `json:"photos"`      // up to 4 sizes of each picture
+}
+
+// venue on the map
+type Venue struct {
+	Location        Location `json:"location"`                    // venue location
+	Title           string   `json:"title"`                       // name of the venue
+	Address         string   `json:"address"`                     // address of the venue
+	FoursquareId    string   `json:"foursquare_id,omitempty"`     // Foursquare identifier
+	FoursquareType  string   `json:"foursquare_type,omitempty"`   // Foursquare type
+	GooglePlaceId   string   `json:"google_place_id,omitempty"`   // Google Places identifier
+	GooglePlaceType string   `json:"google_place_type,omitempty"` // Google Places type
+}
+
+// ======== Methods ========
+
+const BanChatMemberCmd = "banChatMember"
+
+type BanChatMemberParams struct {
+	ChatId         int64  `json:"chat_id"`                   // target chat
+	UserId         uint64 `json:"user_id"`                   // target user
+	UntilDate      int64  `json:"until_date,omitempty"`      // unix time, forever by default
+	RevokeMessages bool   `json:"revoke_messages,omitempty"` // delete all messages from the user
+}
+
+// ban user in a group, supergroup or channel
+func BanChatMember(ctx context.Context, client Caller, params BanChatMemberParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, BanChatMemberCmd, params, &res)
+	return res, err
+}
+
+const CopyMessageCmd = "copyMessage"
+
+type CopyMessageParams struct {
+	ChatId          int64  `json:"chat_id"`                     // target chat
+	MessageThreadId int64  `json:"message_thread_id,omitempty"` // forum topic
+	FromChatId      int64  `json:"from_chat_id"`                // chat of the original message
+	MessageId       uint64 `json:"message_id"`                  // message to copy
+}
+
+// copy message without link to the original
+func CopyMessage(ctx context.Context, client Caller, params CopyMessageParams) (MessageId, error) {
+	var res MessageId
+	err := client.Call(ctx, CopyMessageCmd, params, &res)
+	return res, err
+}
+
+const DeleteMessageCmd = "deleteMessage"
+
+type DeleteMessageParams struct {
+	ChatId    int64  `json:"chat_id"`    // target chat
+	MessageId uint64 `json:"message_id"` // message to delete
+}
+
+// delete message, bots can delete outgoing messages and incoming ones within 48 hours
+func DeleteMessage(ctx context.Context, client Caller, params DeleteMessageParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, DeleteMessageCmd, params, &res)
+	return res, err
+}
+
+const DeleteMessagesCmd = "deleteMessages"
+
+type DeleteMessagesParams struct {
+	ChatId     int64    `json:"chat_id"`     // target chat
+	MessageIds []uint64 `json:"message_ids"` // 1-100 messages to delete
+}
+
+// delete several messages at once, missing ones are skipped
+func DeleteMessages(ctx context.Context, client Caller, params DeleteMessagesParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, DeleteMessagesCmd, params, &res)
+	return res, err
+}
+
+const DeleteWebhookCmd = "deleteWebhook"
+
+type DeleteWebhookParams struct {
+	DropPendingUpdates bool `json:"drop_pending_updates,omitempty"` // drop all pending updates
+}
+
+// remove webhook integration to switch back to getUpdates
+func DeleteWebhook(ctx context.Context, client Caller, params DeleteWebhookParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, DeleteWebhookCmd, params, &res)
+	return res, err
+}
+
+const EditMessageReplyMarkupCmd = "editMessageReplyMarkup"
+
+type EditMessageReplyMarkupParams struct {
+	ChatId      int64       `json:"chat_id"`                // target chat
+	MessageId   uint64      `json:"message_id"`             // message to edit
+	ReplyMarkup ReplyMarkup `json:"reply_markup,omitempty"` // new keyboard, removed if not set
+}
+
+// edit keyboard of message sent by the bot, inline messages are not supported
+func EditMessageReplyMarkup(ctx context.Context, client Caller, params EditMessageReplyMarkupParams) (Message, error) {
+	var res Message
+	err := client.Call(ctx, EditMessageReplyMarkupCmd, params, &res)
+	return res, err
+}
+
+const ForwardMessageCmd = "forwardMessage"
+
+type ForwardMessageParams struct {
+	ChatId          int64  `json:"chat_id"`                     // target chat
+	MessageThreadId int64  `json:"message_thread_id,omitempty"` // forum topic
+	FromChatId      int64  `json:"from_chat_id"`                // chat of the original message
+	MessageId       uint64 `json:"message_id"`                  // message to forward
+}
+
+// forward message with link to the original
+func ForwardMessage(ctx context.Context, client Caller, params ForwardMessageParams) (Message, error) {
+	var res Message
+	err := client.Call(ctx, ForwardMessageCmd, params, &res)
+	return res, err
+}
+
+const GetChatMemberCountCmd = "getChatMemberCount"
+
+type GetChatMemberCountParams struct {
+	ChatId int64 `json:"chat_id"` // target chat
+}
+
+// get number of members in a chat
+func GetChatMemberCount(ctx context.Context, client Caller, params GetChatMemberCountParams) (int, error) {
+	var res int
+	err := client.Call(ctx, GetChatMemberCountCmd, params, &res)
+	return res, err
+}
+
+const GetUserProfilePhotosCmd = "getUserProfilePhotos"
+
+type GetUserProfilePhotosParams struct {
+	UserId uint64 `json:"user_id"`          // target user
+	Offset int    `json:"offset,omitempty"` // number of the first photo
+	Limit  int    `json:"limit,omitempty"`  // 1-100, 100 by default
+}
+
+// get list of profile pictures of a user
+func GetUserProfilePhotos(ctx context.Context, client Caller, params GetUserProfilePhotosParams) (UserProfilePhotos, error) {
+	var res UserProfilePhotos
+	err := client.Call(ctx, GetUserProfilePhotosCmd, params, &res)
+	return res, err
+}
+
+const LeaveChatCmd = "leaveChat"
+
+type LeaveChatParams struct {
+	ChatId int64 `json:"chat_id"` // target chat
+}
+
+// leave group, supergroup or channel
+func LeaveChat(ctx context.Context, client Caller, params LeaveChatParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, LeaveChatCmd, params, &res)
+	return res, err
+}
+
+const PinChatMessageCmd = "pinChatMessage"
+
+type PinChatMessageParams struct {
+	ChatId              int64  `json:"chat_id"`                        // target chat
+	MessageId           uint64 `json:"message_id"`                     // message to pin
+	DisableNotification bool   `json:"disable_notification,omitempty"` // pin silently
+}
+
+// pin message in a chat
+func PinChatMessage(ctx context.Context, client Caller, params PinChatMessageParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, PinChatMessageCmd, params, &res)
+	return res, err
+}
+
+const SendChatActionCmd = "sendChatAction"
+
+type SendChatActionParams struct {
+	ChatId          int64  `json:"chat_id"`                     // target chat
+	MessageThreadId int64  `json:"message_thread_id,omitempty"` // forum topic
+	Action          string `json:"action"`                      // typing, upload_photo and so on
+}
+
+// show bot status in a chat for 5 seconds or until next message
+func SendChatAction(ctx context.Context, client Caller, params SendChatActionParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, SendChatActionCmd, params, &res)
+	return res, err
+}
+
+const SendContactCmd = "sendContact"
+
+type SendContactParams struct {
+	ChatId              int64       `json:"chat_id"`                        // target chat
+	MessageThreadId     int64       `json:"message_thread_id,omitempty"`    // forum topic
+	PhoneNumber         string      `json:"phone_number"`                   // contact's phone number
+	FirstName           string      `json:"first_name"`                     // contact's first name
+	LastName            string      `json:"last_name,omitempty"`            // contact's last name
+	VCard               string      `json:"vcard,omitempty"`                // additional data as vCard
+	DisableNotification bool        `json:"disable_notification,omitempty"` // send silently
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`         // keyboard
+}
+
+// send phone contact
+func SendContact(ctx context.Context, client Caller, params SendContactParams) (Message, error) {
+	var res Message
+	err := client.Call(ctx, SendContactCmd, params, &res)
+	return res, err
+}
+
+const SendDiceCmd = "sendDice"
+
+type SendDiceParams struct {
+	ChatId              int64       `json:"chat_id"`                        // target chat
+	MessageThreadId     int64       `json:"message_thread_id,omitempty"`    // forum topic
+	Emoji               string      `json:"emoji,omitempty"`                // dice by default
+	DisableNotification bool        `json:"disable_notification,omitempty"` // send silently
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`         // keyboard
+}
+
+// send animated emoji with random value
+func SendDice(ctx context.Context, client Caller, params SendDiceParams) (Message, error) {
+	var res Message
+	err := client.Call(ctx, SendDiceCmd, params, &res)
+	return res, err
+}
+
+const SendLocationCmd = "sendLocation"
+
+type SendLocationParams struct {
+	ChatId              int64       `json:"chat_id"`                        // target chat
+	MessageThreadId     int64       `json:"message_thread_id,omitempty"`    // forum topic
+	Latitude            float64     `json:"latitude"`                       // latitude of the location
+	Longitude           float64     `json:"longitude"`                      // longitude of the location
+	HorizontalAccuracy  float64     `json:"horizontal_accuracy,omitempty"`  // radius of uncertainty, meters
+	LivePeriod          int         `json:"live_period,omitempty"`          // seconds the location will be updated
+	DisableNotification bool        `json:"disable_notification,omitempty"` // send silently
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`         // keyboard
+}
+
+// send point on the map
+func SendLocation(ctx context.Context, client Caller, params SendLocationParams) (Message, error) {
+	var res Message
+	err := client.Call(ctx, SendLocationCmd, params, &res)
+	return res, err
+}
+
+const SendVenueCmd = "sendVenue"
+
+type SendVenueParams struct {
+	ChatId              int64       `json:"chat_id"`                        // target chat
+	MessageThreadId     int64       `json:"message_thread_id,omitempty"`    // forum topic
+	Latitude            float64     `json:"latitude"`                       // latitude of the venue
+	Longitude           float64     `json:"longitude"`                      // longitude of the venue
+	Title               string      `json:"title"`                          // name of the venue
+	Address             string      `json:"address"`                        // address of the venue
+	FoursquareId        string      `json:"foursquare_id,omitempty"`        // Foursquare identifier
+	GooglePlaceId       string      `json:"google_place_id,omitempty"`      // Google Places identifier
+	DisableNotification bool        `json:"disable_notification,omitempty"` // send silently
+	ReplyMarkup         ReplyMarkup `json:"reply_markup,omitempty"`         // keyboard
+}
+
+// send information about a venue
+func SendVenue(ctx context.Context, client Caller, params SendVenueParams) (Message, error) {
+	var res Message
+	err := client.Call(ctx, SendVenueCmd, params, &res)
+	return res, err
+}
+
+const SetMessageReactionCmd = "setMessageReaction"
+
+type SetMessageReactionParams struct {
+	ChatId    int64               `json:"chat_id"`            // target chat
+	MessageId uint64              `json:"message_id"`         // target message
+	Reaction  []ReactionTypeEmoji `json:"reaction,omitempty"` // new reactions, empty to remove
+	IsBig     bool                `json:"is_big,omitempty"`   // show big animation
+}
+
+// change reactions of the bot on a message
+func SetMessageReaction(ctx context.Context, client Caller, params SetMessageReactionParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, SetMessageReactionCmd, params, &res)
+	return res, err
+}
+
+const UnbanChatMemberCmd = "unbanChatMember"
+
+type UnbanChatMemberParams struct {
+	ChatId       int64  `json:"chat_id"`                  // target chat
+	UserId       uint64 `json:"user_id"`                  // target user
+	OnlyIfBanned bool   `json:"only_if_banned,omitempty"` // don't remove user who is a member
+}
+
+// unban previously banned user
+func UnbanChatMember(ctx context.Context, client Caller, params UnbanChatMemberParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, UnbanChatMemberCmd, params, &res)
+	return res, err
+}
+
+const UnpinChatMessageCmd = "unpinChatMessage"
+
+type UnpinChatMessageParams struct {
+	ChatId    int64  `json:"chat_id"`              // target chat
+	MessageId uint64 `json:"message_id,omitempty"` // message to unpin, most recent pinned by default
+}
+
+// unpin message in a chat
+func UnpinChatMessage(ctx context.Context, client Caller, params UnpinChatMessageParams) (bool, error) {
+	var res bool
+	err := client.Call(ctx, UnpinChatMessageCmd, params, &res)
+	return res, err
+}
@@ -0,0 +1,146 @@
+package tgapi
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// ======== Incoming updates ========
+
+// user typed "@bot query" in some chat
+type InlineQuery struct {
+	Id       string `json:"id"`
+	From     User   `json:"from"`
+	Query    string `json:"query"`
+	Offset   string `json:"offset"`              // for paging, see NextOffset
+	ChatType string `json:"chat_type,omitempty"` // where query was sent from
+
+	UUID string `json:"-"`
+}
+
+func (m *InlineQuery) User() User { return m.From }
+
+// Origin is private chat with user, query chat is unknown
+func (m *InlineQuery) Origin() Chat {
+	return Chat{Id: int64(m.From.Id), Type: PrivateChat, FirstName: m.From.FirstName}
+}
+
+func (m *InlineQuery) Message() interface{}                                   { return m }
+func (m *InlineQuery) PreProcess(ctx context.Context, client TGClient) error  { return nil }
+func (m *InlineQuery) PostProcess(ctx context.Context, client TGClient) error { return nil }
+
+// user picked one of inline results, needs inline feedback enabled
+type ChosenInlineResult struct {
+	ResultId        string `json:"result_id"`
+	From            User   `json:"from"`
+	Query           string `json:"query"`
+	InlineMessageId string `json:"inline_message_id,omitempty"` // if result has keyboard
+
+	UUID string `json:"-"`
+}
+
+func (m *ChosenInlineResult) User() User { return m.From }
+
+func (m *ChosenInlineResult) Origin() Chat {
+	return Chat{Id: int64(m.From.Id), Type: PrivateChat, FirstName: m.From.FirstName}
+}
+
+func (m *ChosenInlineResult) Message() interface{}                                   { return m }
+func (m *ChosenInlineResult) PreProcess(ctx context.Context, client TGClient) error  { return nil }
+func (m *ChosenInlineResult) PostProcess(ctx context.Context, client TGClient) error { return nil }
+
+// ======== Outgoing requests ========
+
+// InlineQueryResult is one of InlineQueryResultArticle, InlineQueryResultPhoto
+// or InlineQueryResultCachedPhoto
+type InlineQueryResult interface {
+	inlineQueryResult()
+}
+
+// text message sent on choosing result
+type InputTextMessageContent struct {
+	MessageText string          `json:"message_text"`
+	ParseMode   string          `json:"parse_mode,omitempty"`
+	Entities    []MessageEntity `json:"entities,omitempty"`
+}
+
+func MakeInputTextMessageContent(text Text) InputTextMessageContent {
+	return InputTextMessageContent{
+		MessageText: text.Text,
+		ParseMode:   text.ParseMode,
+		Entities:    text.Entities,
+	}
+}
+
+type InlineQueryResultArticle struct {
+	Id                  string                  `json:"id"`
+	Title               string                  `json:"title"`
+	InputMessageContent InputTextMessageContent `json:"input_message_content"`
+	ReplyMarkup         *InlineKeyboard         `json:"reply_markup,omitempty"`
+	URL                 string                  `json:"url,omitempty"`
+	Description         string                  `json:"description,omitempty"`
+	ThumbnailURL        string                  `json:"thumbnail_url,omitempty"`
+}
+
+func (InlineQueryResultArticle) inlineQueryResult() {}
+
+func (r InlineQueryResultArticle) MarshalJSON() ([]byte, error) {
+	type result InlineQueryResultArticle
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		result
+	}{"article", result(r)})
+}
+
+// photo by URL, jpeg only
+type InlineQueryResultPhoto struct {
+	Id              string          `json:"id"`
+	PhotoURL        string          `json:"photo_url"`
+	ThumbnailURL    string          `json:"thumbnail_url"`
+	Title           string          `json:"title,omitempty"`
+	Description     string          `json:"description,omitempty"`
+	Caption         string          `json:"caption,omitempty"`
+	ParseMode       string          `json:"parse_mode,omitempty"`
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+	ReplyMarkup     *InlineKeyboard `json:"reply_markup,omitempty"`
+}
+
+func (InlineQueryResultPhoto) inlineQueryResult() {}
+
+func (r InlineQueryResultPhoto) MarshalJSON() ([]byte, error) {
+	type result InlineQueryResultPhoto
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		result
+	}{"photo", result(r)})
+}
+
+// photo already on telegram servers
+type InlineQueryResultCachedPhoto struct {
+	Id              string          `json:"id"`
+	PhotoFileId     string          `json:"photo_file_id"`
+	Title           string          `json:"title,omitempty"`
+	Description     string          `json:"description,omitempty"`
+	Caption         string          `json:"caption,omitempty"`
+	ParseMode       string          `json:"parse_mode,omitempty"`
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+	ReplyMarkup     *InlineKeyboard `json:"reply_markup,omitempty"`
+}
+
+func (InlineQueryResultCachedPhoto) inlineQueryResult() {}
+
+func (r InlineQueryResultCachedPhoto) MarshalJSON() ([]byte, error) {
+	type result InlineQueryResultCachedPhoto
+	return json.Marshal(struct {
+		Type string `json:"type"`
+		result
+	}{"photo", result(r)})
+}
+
+type AnswerInlineQuery struct {
+	InlineQueryId string              `json:"inline_query_id"`
+	Results       []InlineQueryResult `json:"results"`
+	CacheTime     int                 `json:"cache_time,omitempty"` // seconds, 300 by default
+	IsPersonal    bool                `json:"is_personal,omitempty"`
+	NextOffset    string              `json:"next_offset,omitempty"`
+}
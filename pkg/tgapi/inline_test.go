@@ -0,0 +1,68 @@
+package tgapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAnswerInlineQuery(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		answer   AnswerInlineQuery
+		expected string
+	}{
+		{
+			desc:     "no results",
+			answer:   AnswerInlineQuery{InlineQueryId: "q"},
+			expected: `{"inline_query_id":"q","results":[]}`,
+		},
+		{
+			desc: "article",
+			answer: AnswerInlineQuery{
+				InlineQueryId: "q",
+				Results: []InlineQueryResult{InlineQueryResultArticle{
+					Id:                  "1",
+					Title:               "Run",
+					InputMessageContent: MakeInputTextMessageContent(HTMLText("<b>Run</b>")),
+					Description:         "50%",
+				}},
+				CacheTime:  10,
+				IsPersonal: true,
+			},
+			expected: `{"inline_query_id":"q","cache_time":10,"is_personal":true,"results":[{
+				"type":"article","id":"1","title":"Run","description":"50%",
+				"input_message_content":{"message_text":"<b>Run</b>","parse_mode":"HTML"}}]}`,
+		},
+		{
+			desc: "photos",
+			answer: AnswerInlineQuery{
+				InlineQueryId: "q",
+				Results: []InlineQueryResult{
+					InlineQueryResultPhoto{Id: "1", PhotoURL: "http://x/1.jpg", ThumbnailURL: "http://x/1t.jpg"},
+					InlineQueryResultCachedPhoto{Id: "2", PhotoFileId: "file", Caption: "cap"},
+				},
+				NextOffset: "2",
+			},
+			expected: `{"inline_query_id":"q","next_offset":"2","results":[
+				{"type":"photo","id":"1","photo_url":"http://x/1.jpg","thumbnail_url":"http://x/1t.jpg"},
+				{"type":"photo","id":"2","photo_file_id":"file","caption":"cap"}]}`,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal("/bottoken/"+InlineAnswerCmd, r.URL.Path)
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(err)
+				assert.JSONEq(c.expected, string(body))
+				rw.Write([]byte(`{"ok":true,"result":true}`))
+			})
+			assert.NoError(client.AnswerInlineQuery(context.Background(), c.answer))
+		})
+	}
+}
@@ -2,7 +2,11 @@ package tgapi
 
 import (
 	"context"
+	"fmt"
+	"io"
 	"time"
+
+	"github.com/baldisbk/tgbot/pkg/httputils"
 )
 
 const (
@@ -11,23 +15,86 @@ const (
 	ReceiveCmd = "getUpdates"
 	AnswerCmd  = "answerCallbackQuery"
 	EditCmd    = "editMessageText"
+	WebhookCmd = "setWebhook"
+
+	PhotoCmd    = "sendPhoto"
+	DocumentCmd = "sendDocument"
+	AudioCmd    = "sendAudio"
+	VoiceCmd    = "sendVoice"
+	GetFileCmd  = "getFile"
+
+	SetCommandsCmd    = "setMyCommands"
+	GetCommandsCmd    = "getMyCommands"
+	DeleteCommandsCmd = "deleteMyCommands"
+
+	InlineAnswerCmd = "answerInlineQuery"
+
+	PollCmd     = "sendPoll"
+	StopPollCmd = "stopPoll"
 )
 
 type Config struct {
-	Address string        `yaml:"address" env:"TGBOT_TG_ADDRESS"`
-	Token   string        `yaml:"token" env:"TGBOT_TG_TOKEN"`
-	Timeout time.Duration `yaml:"timeout"`
+	Address    string                     `yaml:"address" env:"TGBOT_TG_ADDRESS"`
+	Token      string                     `yaml:"token" env:"TGBOT_TG_TOKEN"`
+	Timeout    time.Duration              `yaml:"timeout"`
+	RateLimit  RateLimitConfig            `yaml:"rate_limit"`
+	Redact     httputils.RedactConfig     `yaml:"redact"`
+	Middleware httputils.MiddlewareConfig `yaml:"middleware"`
+	// proxy and TLS settings of connection to Bot API
+	Transport httputils.TransportConfig `yaml:"transport"`
+	// download size limit, bytes
+	MaxDownloadSize int64 `yaml:"max_download_size"`
+}
+
+// GoString hides token and proxy password when config is logged with %#v
+func (c Config) GoString() string {
+	type config Config
+	cfg := config(c)
+	cfg.Token = httputils.NewRedactor(httputils.RedactConfig{}, c.Token).String(c.Token)
+	cfg.Transport.Proxy = cfg.Transport.Proxy.Redacted()
+	return fmt.Sprintf("%#v", cfg)
 }
 
 type TGClient interface {
 	Test(ctx context.Context) error
-	GetUpdates(ctx context.Context, offset uint64) ([]Update, uint64, error)
-	EditMessage(ctx context.Context, chat uint64, text string, msgId uint64) (uint64, error)
-	SendMessage(ctx context.Context, chat uint64, text string) (uint64, error)
-	AnswerCallback(ctx context.Context, callbackId string) error
-	EditAnswerKeyboard(ctx context.Context, chat uint64, text string, msgId uint64, keyboard AnswerKeyboard) (uint64, error)
-	CreateAnswerKeyboard(ctx context.Context, chat uint64, text string, keyboard AnswerKeyboard) (uint64, error)
-	EditInputKeyboard(ctx context.Context, chat uint64, text string, msgId uint64, keyboard InlineKeyboard) (uint64, error)
-	CreateInputKeyboard(ctx context.Context, chat uint64, text string, keyboard InlineKeyboard) (uint64, error)
-	DropKeyboard(ctx context.Context, chat uint64, text string) error
+	GetMe(ctx context.Context) (BotInfo, error)
+	// cached by NewClient
+	BotInfo() BotInfo
+	GetUpdates(ctx context.Context, params GetUpdates) ([]Update, uint64, error)
+	EditMessage(ctx context.Context, chat Recipient, text Text, msgId uint64) (uint64, error)
+	SendMessage(ctx context.Context, chat Recipient, text Text) (uint64, error)
+	AnswerCallback(ctx context.Context, answer AnswerCallback) error
+	EditAnswerKeyboard(ctx context.Context, chat Recipient, text Text, msgId uint64, keyboard AnswerKeyboard) (uint64, error)
+	CreateAnswerKeyboard(ctx context.Context, chat Recipient, text Text, keyboard AnswerKeyboard) (uint64, error)
+	EditInputKeyboard(ctx context.Context, chat Recipient, text Text, msgId uint64, keyboard InlineKeyboard) (uint64, error)
+	CreateInputKeyboard(ctx context.Context, chat Recipient, text Text, keyboard InlineKeyboard) (uint64, error)
+	DropKeyboard(ctx context.Context, chat Recipient, text Text) error
+	SendForceReply(ctx context.Context, chat Recipient, text Text, reply ForceReply) (uint64, error)
+	SetWebhook(ctx context.Context, webhook SetWebhook) error
+	SendPhoto(ctx context.Context, chat Recipient, photo InputFile, caption Text, markup ReplyMarkup) (uint64, error)
+	SendDocument(ctx context.Context, chat Recipient, document InputFile, caption Text, markup ReplyMarkup) (uint64, error)
+	SendAudio(ctx context.Context, chat Recipient, audio InputFile, caption Text, markup ReplyMarkup) (uint64, error)
+	SendVoice(ctx context.Context, chat Recipient, voice InputFile, caption Text, markup ReplyMarkup) (uint64, error)
+	GetFile(ctx context.Context, fileId string) (File, error)
+	DownloadFile(ctx context.Context, fileId string) (io.ReadCloser, error)
+	SetMyCommands(ctx context.Context, commands []BotCommand, scope *BotCommandScope, language string) error
+	GetMyCommands(ctx context.Context, scope *BotCommandScope, language string) ([]BotCommand, error)
+	DeleteMyCommands(ctx context.Context, scope *BotCommandScope, language string) error
+	AnswerInlineQuery(ctx context.Context, answer AnswerInlineQuery) error
+	DeleteMessage(ctx context.Context, chat Recipient, msgId uint64) error
+	DeleteMessages(ctx context.Context, chat Recipient, msgIds []uint64) error
+	// nil keyboard removes it
+	EditReplyMarkup(ctx context.Context, chat Recipient, msgId uint64, keyboard *InlineKeyboard) error
+	PinMessage(ctx context.Context, chat Recipient, msgId uint64, silent bool) error
+	// most recent pinned message if msgId is 0
+	UnpinMessage(ctx context.Context, chat Recipient, msgId uint64) error
+	CopyMessage(ctx context.Context, to Recipient, from Recipient, msgId uint64) (uint64, error)
+	ForwardMessage(ctx context.Context, to Recipient, from Recipient, msgId uint64) (uint64, error)
+	// shown for 5 seconds or until next message
+	SendChatAction(ctx context.Context, chat Recipient, action string) error
+	// sent message has poll id to match answers with
+	SendPoll(ctx context.Context, chat Recipient, poll SendPoll) (Message, error)
+	StopPoll(ctx context.Context, chat Recipient, msgId uint64) (Poll, error)
+	// any method, see generated.go for typed params and results
+	Caller
 }
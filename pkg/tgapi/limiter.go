@@ -0,0 +1,134 @@
+package tgapi
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+)
+
+const (
+	defaultGlobalRate = 30 // messages per second, all chats
+	defaultChatRate   = 1  // messages per second, single chat
+
+	// forget chats idle for a while when there are too many
+	limiterChatsCleanup = 1024
+)
+
+type RateLimitConfig struct {
+	Disabled bool    `yaml:"disabled"`
+	Global   float64 `yaml:"global"`
+	PerChat  float64 `yaml:"per_chat"`
+}
+
+// limiter queues outgoing messages to keep within telegram limits
+type limiter struct {
+	mx    sync.Mutex
+	clock clockwork.Clock
+
+	global  time.Duration // min interval between any messages
+	perChat time.Duration // min interval between messages to same chat
+
+	next  time.Time           // next free global slot
+	chats map[int64]time.Time // next free slot per chat
+}
+
+func interval(rate, dflt float64) time.Duration {
+	if rate <= 0 {
+		rate = dflt
+	}
+	return time.Duration(float64(time.Second) / rate)
+}
+
+func newLimiter(cfg RateLimitConfig, clock clockwork.Clock) *limiter {
+	if cfg.Disabled {
+		return nil
+	}
+	return &limiter{
+		clock:   clock,
+		global:  interval(cfg.Global, defaultGlobalRate),
+		perChat: interval(cfg.PerChat, defaultChatRate),
+		chats:   map[int64]time.Time{},
+	}
+}
+
+// wait blocks until message to chat is allowed, slots are freed if cancelled
+func (l *limiter) wait(ctx context.Context, chat int64) error {
+	if l == nil {
+		return nil
+	}
+	// chat slot first, so busy chats don't hold global queue
+	chatAt, releaseChat := l.reserveChat(chat)
+	if err := l.sleep(ctx, chatAt); err != nil {
+		releaseChat()
+		return err
+	}
+	at, release := l.reserveGlobal()
+	if err := l.sleep(ctx, at); err != nil {
+		release()
+		releaseChat()
+		return err
+	}
+	return nil
+}
+
+// reserveChat returns time of chat slot and func to give it back
+func (l *limiter) reserveChat(chat int64) (time.Time, func()) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	now := l.clock.Now()
+	if len(l.chats) >= limiterChatsCleanup {
+		for id, next := range l.chats {
+			if next.Before(now) {
+				delete(l.chats, id)
+			}
+		}
+	}
+	at := now
+	if next, ok := l.chats[chat]; ok && next.After(at) {
+		at = next
+	}
+	next := at.Add(l.perChat)
+	l.chats[chat] = next
+	return at, func() {
+		l.mx.Lock()
+		defer l.mx.Unlock()
+		// slots reserved later are counted from this one, it stays then
+		if l.chats[chat].Equal(next) {
+			l.chats[chat] = at
+		}
+	}
+}
+
+// reserveGlobal returns time of global slot and func to give it back
+func (l *limiter) reserveGlobal() (time.Time, func()) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	at := l.clock.Now()
+	if l.next.After(at) {
+		at = l.next
+	}
+	next := at.Add(l.global)
+	l.next = next
+	return at, func() {
+		l.mx.Lock()
+		defer l.mx.Unlock()
+		if l.next.Equal(next) {
+			l.next = at
+		}
+	}
+}
+
+func (l *limiter) sleep(ctx context.Context, at time.Time) error {
+	d := at.Sub(l.clock.Now())
+	if d <= 0 {
+		return nil
+	}
+	select {
+	case <-l.clock.After(d):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
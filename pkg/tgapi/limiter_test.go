@@ -0,0 +1,126 @@
+package tgapi
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/jonboulle/clockwork"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLimiter(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		chats []int64
+		// when messages are let through, in order
+		times []time.Duration
+	}{
+		{
+			desc:  "single",
+			chats: []int64{1},
+			times: []time.Duration{0},
+		},
+		{
+			desc:  "same chat",
+			chats: []int64{1, 1, 1},
+			times: []time.Duration{0, time.Second, 2 * time.Second},
+		},
+		{
+			desc:  "different chats",
+			chats: []int64{1, 2, 3},
+			times: []time.Duration{0, 100 * time.Millisecond, 200 * time.Millisecond},
+		},
+		{
+			desc:  "busy chat does not hold others",
+			chats: []int64{1, 1, 2},
+			times: []time.Duration{0, 100 * time.Millisecond, time.Second},
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			ctx := context.Background()
+			clock := clockwork.NewFakeClock()
+			start := clock.Now()
+			lim := newLimiter(RateLimitConfig{Global: 10, PerChat: 1}, clock)
+
+			passed := make(chan time.Duration, len(c.chats))
+			for _, chat := range c.chats {
+				go func(chat int64) {
+					if err := lim.wait(ctx, chat); err != nil {
+						t.Error(err)
+					}
+					passed <- clock.Now().Sub(start)
+				}(chat)
+			}
+			var times []time.Duration
+			for len(times) < len(c.chats) {
+				select {
+				case d := <-passed:
+					times = append(times, d)
+				case <-time.After(10 * time.Millisecond):
+					clock.Advance(50 * time.Millisecond)
+				}
+			}
+			sort.Slice(times, func(i, j int) bool { return times[i] < times[j] })
+			assert.Equal(c.times, times)
+		})
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	clock := clockwork.NewFakeClock()
+	lim := newLimiter(RateLimitConfig{}, clock)
+	require.NoError(t, lim.wait(context.Background(), 1))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.Error(t, lim.wait(ctx, 1))
+
+	// cancelled wait does not hold slot
+	clock.Advance(time.Second)
+	passed := make(chan error)
+	go func() { passed <- lim.wait(context.Background(), 1) }()
+	select {
+	case err := <-passed:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("slot of cancelled wait is not freed")
+	}
+}
+
+func TestCallRateLimit(t *testing.T) {
+	testCases := []struct {
+		desc    string
+		method  string
+		limited bool
+	}{
+		{desc: "send", method: SendDiceCmd, limited: true},
+		{desc: "copy", method: CopyMessageCmd, limited: true},
+		{desc: "get", method: GetChatMemberCountCmd},
+		{desc: "chat action", method: SendChatActionCmd},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				rw.Write([]byte(`{"ok":true,"result":true}`))
+			})
+			client.limiter = newLimiter(RateLimitConfig{}, clockwork.NewFakeClock())
+			// chat is busy until fake clock advances
+			assert.NoError(client.limiter.wait(context.Background(), 1))
+
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+			err := client.Call(ctx, c.method, map[string]interface{}{"chat_id": 1}, nil)
+			if c.limited {
+				assert.ErrorIs(err, context.DeadlineExceeded)
+			} else {
+				assert.NoError(err)
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package tgapi
+
+import "context"
+
+// member statuses
+const (
+	MemberCreator       = "creator"
+	MemberAdministrator = "administrator"
+	MemberMember        = "member"
+	MemberRestricted    = "restricted"
+	MemberLeft          = "left"
+	MemberKicked        = "kicked" // or blocked bot in private chat
+)
+
+type ChatMember struct {
+	User     User   `json:"user"`
+	Status   string `json:"status"`
+	IsMember bool   `json:"is_member,omitempty"` // for restricted only
+}
+
+// Present is true if member is in chat
+func (m ChatMember) Present() bool {
+	switch m.Status {
+	case MemberCreator, MemberAdministrator, MemberMember:
+		return true
+	case MemberRestricted:
+		return m.IsMember
+	}
+	return false
+}
+
+// status of bot (my_chat_member) or other member (chat_member) changed
+type ChatMemberUpdated struct {
+	Chat          Chat       `json:"chat"`
+	From          User       `json:"from"` // who made the change
+	Date          int64      `json:"date"`
+	OldChatMember ChatMember `json:"old_chat_member"`
+	NewChatMember ChatMember `json:"new_chat_member"`
+
+	UUID string `json:"-"`
+}
+
+func (m *ChatMemberUpdated) User() User                                             { return m.From }
+func (m *ChatMemberUpdated) Origin() Chat                                           { return m.Chat }
+func (m *ChatMemberUpdated) Message() interface{}                                   { return m }
+func (m *ChatMemberUpdated) PreProcess(ctx context.Context, client TGClient) error  { return nil }
+func (m *ChatMemberUpdated) PostProcess(ctx context.Context, client TGClient) error { return nil }
@@ -0,0 +1,16 @@
+package tgapi
+
+// params of deleteMessage, editMessageReplyMarkup, copyMessage and so on are generated from spec
+
+// deleteMessages accepts up to 100 ids at once
+const maxDeleteMessages = 100
+
+// chat actions
+const (
+	TypingAction         = "typing"
+	UploadPhotoAction    = "upload_photo"
+	UploadDocumentAction = "upload_document"
+	RecordVoiceAction    = "record_voice"
+	UploadVoiceAction    = "upload_voice"
+	FindLocationAction   = "find_location"
+)
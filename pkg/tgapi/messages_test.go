@@ -0,0 +1,126 @@
+package tgapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestManageMessages(t *testing.T) {
+	from := ToChat(1)
+	to := Recipient{ChatId: -1001, ThreadId: 7}
+	testCases := []struct {
+		desc     string
+		call     func(TGClient) error
+		cmd      string
+		expected string
+		result   string // true if empty
+	}{
+		{
+			desc:     "delete",
+			call:     func(c TGClient) error { return c.DeleteMessage(context.Background(), from, 42) },
+			cmd:      DeleteMessageCmd,
+			expected: `{"chat_id":1,"message_id":42}`,
+		},
+		{
+			desc:     "delete many",
+			call:     func(c TGClient) error { return c.DeleteMessages(context.Background(), from, []uint64{1, 2}) },
+			cmd:      DeleteMessagesCmd,
+			expected: `{"chat_id":1,"message_ids":[1,2]}`,
+		},
+		{
+			desc:     "drop keyboard",
+			call:     func(c TGClient) error { return c.EditReplyMarkup(context.Background(), from, 42, nil) },
+			cmd:      EditMessageReplyMarkupCmd,
+			expected: `{"chat_id":1,"message_id":42}`,
+			result:   `{"message_id":42,"chat":{"id":1}}`,
+		},
+		{
+			desc: "edit keyboard",
+			call: func(c TGClient) error {
+				return c.EditReplyMarkup(context.Background(), from, 42, &InlineKeyboard{
+					InlineKeyboard: [][]InlineKeyboardButton{{{Text: "OK", CallbackData: "ok"}}},
+				})
+			},
+			cmd:      EditMessageReplyMarkupCmd,
+			expected: `{"chat_id":1,"message_id":42,"reply_markup":{"inline_keyboard":[[{"text":"OK","callback_data":"ok"}]]}}`,
+			result:   `{"message_id":42,"chat":{"id":1}}`,
+		},
+		{
+			desc:     "pin",
+			call:     func(c TGClient) error { return c.PinMessage(context.Background(), from, 42, true) },
+			cmd:      PinChatMessageCmd,
+			expected: `{"chat_id":1,"message_id":42,"disable_notification":true}`,
+		},
+		{
+			desc:     "unpin last",
+			call:     func(c TGClient) error { return c.UnpinMessage(context.Background(), from, 0) },
+			cmd:      UnpinChatMessageCmd,
+			expected: `{"chat_id":1}`,
+		},
+		{
+			desc: "copy",
+			call: func(c TGClient) error {
+				_, err := c.CopyMessage(context.Background(), to, from, 42)
+				return err
+			},
+			cmd:      CopyMessageCmd,
+			expected: `{"chat_id":-1001,"message_thread_id":7,"from_chat_id":1,"message_id":42}`,
+			result:   `{"message_id":43}`,
+		},
+		{
+			desc: "forward",
+			call: func(c TGClient) error {
+				_, err := c.ForwardMessage(context.Background(), to, from, 42)
+				return err
+			},
+			cmd:      ForwardMessageCmd,
+			expected: `{"chat_id":-1001,"message_thread_id":7,"from_chat_id":1,"message_id":42}`,
+			result:   `{"message_id":43,"chat":{"id":-1001}}`,
+		},
+		{
+			desc:     "typing in topic",
+			call:     func(c TGClient) error { return c.SendChatAction(context.Background(), to, TypingAction) },
+			cmd:      SendChatActionCmd,
+			expected: `{"chat_id":-1001,"message_thread_id":7,"action":"typing"}`,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal("/bottoken/"+c.cmd, r.URL.Path)
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(err)
+				assert.JSONEq(c.expected, string(body))
+				result := c.result
+				if result == "" {
+					result = "true"
+				}
+				rw.Write([]byte(`{"ok":true,"result":` + result + `}`))
+			})
+			assert.NoError(c.call(client))
+		})
+	}
+}
+
+func TestDeleteMessagesBatches(t *testing.T) {
+	assert := require.New(t)
+	var batches []int
+	client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+		var req DeleteMessagesParams
+		assert.NoError(json.NewDecoder(r.Body).Decode(&req))
+		batches = append(batches, len(req.MessageIds))
+		rw.Write([]byte(`{"ok":true,"result":true}`))
+	})
+	ids := make([]uint64, 250)
+	for i := range ids {
+		ids[i] = uint64(i + 1)
+	}
+	assert.NoError(client.DeleteMessages(context.Background(), ToChat(1), ids))
+	assert.Equal([]int{100, 100, 50}, batches)
+}
@@ -2,6 +2,7 @@ package tgapi
 
 import (
 	"context"
+	"io"
 
 	"github.com/stretchr/testify/mock"
 )
@@ -19,47 +20,175 @@ func (tg *tgMock) Test(ctx context.Context) error {
 	return args.Error(0)
 }
 
-func (tg *tgMock) GetUpdates(ctx context.Context, offset uint64) ([]Update, uint64, error) {
+func (tg *tgMock) GetMe(ctx context.Context) (BotInfo, error) {
 	args := tg.Called(ctx)
+	return args[0].(BotInfo), args.Error(1)
+}
+
+func (tg *tgMock) BotInfo() BotInfo {
+	args := tg.Called()
+	return args[0].(BotInfo)
+}
+
+func (tg *tgMock) GetUpdates(ctx context.Context, params GetUpdates) ([]Update, uint64, error) {
+	args := tg.Called(ctx, params)
 	return args[0].([]Update), args[1].(uint64), args.Error(2)
 }
 
-func (tg *tgMock) EditMessage(ctx context.Context, chat uint64, text string, msgId uint64) (uint64, error) {
+func (tg *tgMock) EditMessage(ctx context.Context, chat Recipient, text Text, msgId uint64) (uint64, error) {
 	args := tg.Called(ctx, chat, text, msgId)
 	return args[0].(uint64), args.Error(1)
 }
 
-func (tg *tgMock) SendMessage(ctx context.Context, chat uint64, text string) (uint64, error) {
+func (tg *tgMock) SendMessage(ctx context.Context, chat Recipient, text Text) (uint64, error) {
 	args := tg.Called(ctx, chat, text)
 	return args[0].(uint64), args.Error(1)
 }
 
-func (tg *tgMock) AnswerCallback(ctx context.Context, callbackId string) error {
-	args := tg.Called(ctx, callbackId)
+func (tg *tgMock) AnswerCallback(ctx context.Context, answer AnswerCallback) error {
+	args := tg.Called(ctx, answer)
 	return args.Error(0)
 }
 
-func (tg *tgMock) EditAnswerKeyboard(ctx context.Context, chat uint64, text string, msgId uint64, keyboard AnswerKeyboard) (uint64, error) {
+func (tg *tgMock) EditAnswerKeyboard(ctx context.Context, chat Recipient, text Text, msgId uint64, keyboard AnswerKeyboard) (uint64, error) {
 	args := tg.Called(ctx, chat, text, msgId, keyboard)
 	return args[0].(uint64), args.Error(1)
 }
 
-func (tg *tgMock) CreateAnswerKeyboard(ctx context.Context, chat uint64, text string, keyboard AnswerKeyboard) (uint64, error) {
+func (tg *tgMock) CreateAnswerKeyboard(ctx context.Context, chat Recipient, text Text, keyboard AnswerKeyboard) (uint64, error) {
 	args := tg.Called(ctx, chat, text, keyboard)
 	return args[0].(uint64), args.Error(1)
 }
 
-func (tg *tgMock) EditInputKeyboard(ctx context.Context, chat uint64, text string, msgId uint64, keyboard InlineKeyboard) (uint64, error) {
+func (tg *tgMock) EditInputKeyboard(ctx context.Context, chat Recipient, text Text, msgId uint64, keyboard InlineKeyboard) (uint64, error) {
 	args := tg.Called(ctx, chat, text, msgId, keyboard)
 	return args[0].(uint64), args.Error(1)
 }
 
-func (tg *tgMock) CreateInputKeyboard(ctx context.Context, chat uint64, text string, keyboard InlineKeyboard) (uint64, error) {
+func (tg *tgMock) CreateInputKeyboard(ctx context.Context, chat Recipient, text Text, keyboard InlineKeyboard) (uint64, error) {
 	args := tg.Called(ctx, chat, text, keyboard)
 	return args[0].(uint64), args.Error(1)
 }
 
-func (tg *tgMock) DropKeyboard(ctx context.Context, chat uint64, text string) error {
+func (tg *tgMock) DropKeyboard(ctx context.Context, chat Recipient, text Text) error {
 	args := tg.Called(ctx, chat, text)
 	return args.Error(0)
 }
+
+func (tg *tgMock) SendForceReply(ctx context.Context, chat Recipient, text Text, reply ForceReply) (uint64, error) {
+	args := tg.Called(ctx, chat, text, reply)
+	return args[0].(uint64), args.Error(1)
+}
+
+func (tg *tgMock) SetWebhook(ctx context.Context, webhook SetWebhook) error {
+	args := tg.Called(ctx, webhook)
+	return args.Error(0)
+}
+
+func (tg *tgMock) SendPhoto(ctx context.Context, chat Recipient, photo InputFile, caption Text, markup ReplyMarkup) (uint64, error) {
+	args := tg.Called(ctx, chat, photo, caption, markup)
+	return args[0].(uint64), args.Error(1)
+}
+
+func (tg *tgMock) SendDocument(ctx context.Context, chat Recipient, document InputFile, caption Text, markup ReplyMarkup) (uint64, error) {
+	args := tg.Called(ctx, chat, document, caption, markup)
+	return args[0].(uint64), args.Error(1)
+}
+
+func (tg *tgMock) SendAudio(ctx context.Context, chat Recipient, audio InputFile, caption Text, markup ReplyMarkup) (uint64, error) {
+	args := tg.Called(ctx, chat, audio, caption, markup)
+	return args[0].(uint64), args.Error(1)
+}
+
+func (tg *tgMock) SendVoice(ctx context.Context, chat Recipient, voice InputFile, caption Text, markup ReplyMarkup) (uint64, error) {
+	args := tg.Called(ctx, chat, voice, caption, markup)
+	return args[0].(uint64), args.Error(1)
+}
+
+func (tg *tgMock) GetFile(ctx context.Context, fileId string) (File, error) {
+	args := tg.Called(ctx, fileId)
+	return args[0].(File), args.Error(1)
+}
+
+func (tg *tgMock) DownloadFile(ctx context.Context, fileId string) (io.ReadCloser, error) {
+	args := tg.Called(ctx, fileId)
+	if args[0] == nil {
+		return nil, args.Error(1)
+	}
+	return args[0].(io.ReadCloser), args.Error(1)
+}
+
+func (tg *tgMock) SetMyCommands(ctx context.Context, commands []BotCommand, scope *BotCommandScope, language string) error {
+	args := tg.Called(ctx, commands, scope, language)
+	return args.Error(0)
+}
+
+func (tg *tgMock) GetMyCommands(ctx context.Context, scope *BotCommandScope, language string) ([]BotCommand, error) {
+	args := tg.Called(ctx, scope, language)
+	return args[0].([]BotCommand), args.Error(1)
+}
+
+func (tg *tgMock) DeleteMyCommands(ctx context.Context, scope *BotCommandScope, language string) error {
+	args := tg.Called(ctx, scope, language)
+	return args.Error(0)
+}
+
+func (tg *tgMock) AnswerInlineQuery(ctx context.Context, answer AnswerInlineQuery) error {
+	args := tg.Called(ctx, answer)
+	return args.Error(0)
+}
+
+func (tg *tgMock) DeleteMessage(ctx context.Context, chat Recipient, msgId uint64) error {
+	args := tg.Called(ctx, chat, msgId)
+	return args.Error(0)
+}
+
+func (tg *tgMock) DeleteMessages(ctx context.Context, chat Recipient, msgIds []uint64) error {
+	args := tg.Called(ctx, chat, msgIds)
+	return args.Error(0)
+}
+
+func (tg *tgMock) EditReplyMarkup(ctx context.Context, chat Recipient, msgId uint64, keyboard *InlineKeyboard) error {
+	args := tg.Called(ctx, chat, msgId, keyboard)
+	return args.Error(0)
+}
+
+func (tg *tgMock) PinMessage(ctx context.Context, chat Recipient, msgId uint64, silent bool) error {
+	args := tg.Called(ctx, chat, msgId, silent)
+	return args.Error(0)
+}
+
+func (tg *tgMock) UnpinMessage(ctx context.Context, chat Recipient, msgId uint64) error {
+	args := tg.Called(ctx, chat, msgId)
+	return args.Error(0)
+}
+
+func (tg *tgMock) CopyMessage(ctx context.Context, to Recipient, from Recipient, msgId uint64) (uint64, error) {
+	args := tg.Called(ctx, to, from, msgId)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (tg *tgMock) ForwardMessage(ctx context.Context, to Recipient, from Recipient, msgId uint64) (uint64, error) {
+	args := tg.Called(ctx, to, from, msgId)
+	return args.Get(0).(uint64), args.Error(1)
+}
+
+func (tg *tgMock) SendChatAction(ctx context.Context, chat Recipient, action string) error {
+	args := tg.Called(ctx, chat, action)
+	return args.Error(0)
+}
+
+func (tg *tgMock) SendPoll(ctx context.Context, chat Recipient, poll SendPoll) (Message, error) {
+	args := tg.Called(ctx, chat, poll)
+	return args[0].(Message), args.Error(1)
+}
+
+func (tg *tgMock) StopPoll(ctx context.Context, chat Recipient, msgId uint64) (Poll, error) {
+	args := tg.Called(ctx, chat, msgId)
+	return args[0].(Poll), args.Error(1)
+}
+
+func (tg *tgMock) Call(ctx context.Context, method string, params interface{}, result interface{}) error {
+	args := tg.Called(ctx, method, params, result)
+	return args.Error(0)
+}
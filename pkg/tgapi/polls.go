@@ -0,0 +1,91 @@
+package tgapi
+
+import "context"
+
+// poll types
+const (
+	RegularPoll = "regular"
+	QuizPoll    = "quiz"
+)
+
+type PollOption struct {
+	Text       string `json:"text"`
+	VoterCount int    `json:"voter_count"`
+}
+
+// poll state, sent as update when it changes
+type Poll struct {
+	Id                    string       `json:"id"`
+	Question              string       `json:"question"`
+	Options               []PollOption `json:"options"`
+	TotalVoterCount       int          `json:"total_voter_count"`
+	IsClosed              bool         `json:"is_closed"`
+	IsAnonymous           bool         `json:"is_anonymous"`
+	Type                  string       `json:"type"`
+	AllowsMultipleAnswers bool         `json:"allows_multiple_answers"`
+	CorrectOptionId       *int         `json:"correct_option_id,omitempty"` // quiz only
+	Explanation           string       `json:"explanation,omitempty"`       // quiz only
+
+	UUID string `json:"-"`
+}
+
+// user voted in non-anonymous poll, no options if vote is retracted
+type PollAnswer struct {
+	PollId    string `json:"poll_id"`
+	Voter     User   `json:"user"`
+	OptionIds []int  `json:"option_ids"`
+
+	UUID string `json:"-"`
+}
+
+func (m *PollAnswer) User() User { return m.Voter }
+
+// Origin is private chat with voter, poll chat is unknown
+func (m *PollAnswer) Origin() Chat {
+	return Chat{Id: int64(m.Voter.Id), Type: PrivateChat, FirstName: m.Voter.FirstName}
+}
+
+func (m *PollAnswer) Message() interface{}                                   { return m }
+func (m *PollAnswer) PreProcess(ctx context.Context, client TGClient) error  { return nil }
+func (m *PollAnswer) PostProcess(ctx context.Context, client TGClient) error { return nil }
+
+type InputPollOption struct {
+	Text string `json:"text"`
+}
+
+type SendPoll struct {
+	ChatId          int64             `json:"chat_id"`
+	MessageThreadId int64             `json:"message_thread_id,omitempty"`
+	Question        string            `json:"question"`
+	Options         []InputPollOption `json:"options"` // 2-10
+	// answers are sent to bot only for non-anonymous polls
+	IsAnonymous           bool            `json:"is_anonymous"`
+	Type                  string          `json:"type,omitempty"` // regular by default
+	AllowsMultipleAnswers bool            `json:"allows_multiple_answers,omitempty"`
+	CorrectOptionId       *int            `json:"correct_option_id,omitempty"` // required for quiz
+	Explanation           string          `json:"explanation,omitempty"`
+	OpenPeriod            int             `json:"open_period,omitempty"` // seconds, 5-600
+	ReplyMarkup           *InlineKeyboard `json:"reply_markup,omitempty"`
+}
+
+// MakePoll is non-anonymous regular poll
+func MakePoll(question string, options ...string) SendPoll {
+	res := SendPoll{Question: question, Type: RegularPoll}
+	for _, option := range options {
+		res.Options = append(res.Options, InputPollOption{Text: option})
+	}
+	return res
+}
+
+// MakeQuiz is non-anonymous quiz with one correct option
+func MakeQuiz(question string, correct int, options ...string) SendPoll {
+	res := MakePoll(question, options...)
+	res.Type = QuizPoll
+	res.CorrectOptionId = &correct
+	return res
+}
+
+type StopPoll struct {
+	ChatId    int64  `json:"chat_id"`
+	MessageId uint64 `json:"message_id"`
+}
@@ -0,0 +1,63 @@
+package tgapi
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSendPoll(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		poll     SendPoll
+		expected string
+	}{
+		{
+			desc: "regular",
+			poll: MakePoll("How hard?", "Easy", "Hard"),
+			expected: `{"chat_id":1,"question":"How hard?","is_anonymous":false,"type":"regular",
+				"options":[{"text":"Easy"},{"text":"Hard"}]}`,
+		},
+		{
+			desc: "quiz",
+			poll: MakeQuiz("2+2?", 0, "4", "5"),
+			expected: `{"chat_id":1,"question":"2+2?","is_anonymous":false,"type":"quiz","correct_option_id":0,
+				"options":[{"text":"4"},{"text":"5"}]}`,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal("/bottoken/"+PollCmd, r.URL.Path)
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(err)
+				assert.JSONEq(c.expected, string(body))
+				rw.Write([]byte(`{"ok":true,"result":{"message_id":42,"poll":{"id":"p1","question":"q"}}}`))
+			})
+			msg, err := client.SendPoll(context.Background(), ToChat(1), c.poll)
+			assert.NoError(err)
+			assert.Equal(uint64(42), msg.MessageId)
+			assert.Equal("p1", msg.Poll.Id)
+		})
+	}
+}
+
+func TestStopPoll(t *testing.T) {
+	assert := require.New(t)
+	client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal("/bottoken/"+StopPollCmd, r.URL.Path)
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(err)
+		assert.JSONEq(`{"chat_id":1,"message_id":42}`, string(body))
+		rw.Write([]byte(`{"ok":true,"result":{"id":"p1","question":"q","is_closed":true,"total_voter_count":1,
+			"options":[{"text":"Easy","voter_count":1},{"text":"Hard","voter_count":0}]}}`))
+	})
+	poll, err := client.StopPoll(context.Background(), ToChat(1), 42)
+	assert.NoError(err)
+	assert.True(poll.IsClosed)
+	assert.Equal([]PollOption{{Text: "Easy", VoterCount: 1}, {Text: "Hard"}}, poll.Options)
+}
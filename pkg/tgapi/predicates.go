@@ -0,0 +1,82 @@
+package tgapi
+
+import (
+	"context"
+
+	"github.com/baldisbk/tgbot/pkg/statemachine"
+)
+
+type MessageKind string
+
+const (
+	TextMessage     MessageKind = "text"
+	PhotoMessage    MessageKind = "photo"
+	DocumentMessage MessageKind = "document"
+	AudioMessage    MessageKind = "audio"
+	VoiceMessage    MessageKind = "voice"
+	VideoMessage    MessageKind = "video"
+	StickerMessage  MessageKind = "sticker"
+	ContactMessage  MessageKind = "contact"
+	LocationMessage MessageKind = "location"
+	OtherMessage    MessageKind = "other" // service messages and what is not modelled yet
+)
+
+func (m *Message) Kind() MessageKind {
+	switch {
+	case len(m.Photo) != 0:
+		return PhotoMessage
+	case m.Document != nil:
+		return DocumentMessage
+	case m.Audio != nil:
+		return AudioMessage
+	case m.Voice != nil:
+		return VoiceMessage
+	case m.Video != nil:
+		return VideoMessage
+	case m.Sticker != nil:
+		return StickerMessage
+	case m.Contact != nil:
+		return ContactMessage
+	case m.Location != nil:
+		return LocationMessage
+	case m.Text != "":
+		return TextMessage
+	}
+	return OtherMessage
+}
+
+// LargestPhoto returns best quality size of photo, if any
+func (m *Message) LargestPhoto() *PhotoSize {
+	var res *PhotoSize
+	for i := range m.Photo {
+		if res == nil || m.Photo[i].Width*m.Photo[i].Height > res.Width*res.Height {
+			res = &m.Photo[i]
+		}
+	}
+	return res
+}
+
+// IsMessageKind matches messages of any of given kinds
+func IsMessageKind(kinds ...MessageKind) statemachine.SMPredicate {
+	return func(ctx context.Context, state string, input interface{}) bool {
+		msg, ok := input.(*Message)
+		if !ok || msg == nil {
+			return false
+		}
+		kind := msg.Kind()
+		for _, k := range kinds {
+			if k == kind {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+func IsText(ctx context.Context, state string, input interface{}) bool {
+	return IsMessageKind(TextMessage)(ctx, state, input)
+}
+
+func IsMedia(ctx context.Context, state string, input interface{}) bool {
+	return IsMessageKind(PhotoMessage, DocumentMessage, AudioMessage, VoiceMessage, VideoMessage, StickerMessage)(ctx, state, input)
+}
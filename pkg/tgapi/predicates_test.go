@@ -0,0 +1,99 @@
+package tgapi
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMessageKind(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		json  string
+		kind  MessageKind
+		media bool
+	}{
+		{
+			desc: "text",
+			json: `{"message_id":1,"text":"hello","entities":[{"type":"bold","offset":0,"length":5}]}`,
+			kind: TextMessage,
+		},
+		{
+			desc:  "photo",
+			json:  `{"message_id":1,"photo":[{"file_id":"a","width":90,"height":60},{"file_id":"b","width":900,"height":600}],"caption":"look"}`,
+			kind:  PhotoMessage,
+			media: true,
+		},
+		{
+			desc:  "document",
+			json:  `{"message_id":1,"document":{"file_id":"a","file_name":"data.csv","mime_type":"text/csv"}}`,
+			kind:  DocumentMessage,
+			media: true,
+		},
+		{
+			desc:  "audio",
+			json:  `{"message_id":1,"audio":{"file_id":"a","duration":180,"title":"song"}}`,
+			kind:  AudioMessage,
+			media: true,
+		},
+		{
+			desc:  "voice",
+			json:  `{"message_id":1,"voice":{"file_id":"a","duration":3}}`,
+			kind:  VoiceMessage,
+			media: true,
+		},
+		{
+			desc:  "video",
+			json:  `{"message_id":1,"video":{"file_id":"a","width":1,"height":1,"duration":3}}`,
+			kind:  VideoMessage,
+			media: true,
+		},
+		{
+			desc:  "sticker",
+			json:  `{"message_id":1,"sticker":{"file_id":"a","type":"regular","emoji":"👍"}}`,
+			kind:  StickerMessage,
+			media: true,
+		},
+		{
+			desc: "contact",
+			json: `{"message_id":1,"contact":{"phone_number":"+100","first_name":"John"}}`,
+			kind: ContactMessage,
+		},
+		{
+			desc: "location",
+			json: `{"message_id":1,"location":{"latitude":1.5,"longitude":2.5}}`,
+			kind: LocationMessage,
+		},
+		{
+			desc: "other",
+			json: `{"message_id":1,"new_chat_title":"title"}`,
+			kind: OtherMessage,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			ctx := context.Background()
+			var msg Message
+			assert.NoError(json.Unmarshal([]byte(c.json), &msg))
+			assert.Equal(c.kind, msg.Kind())
+			assert.True(IsMessageKind(c.kind)(ctx, "", &msg))
+			assert.Equal(c.kind == TextMessage, IsText(ctx, "", &msg))
+			assert.Equal(c.media, IsMedia(ctx, "", &msg))
+			assert.False(IsMessageKind(c.kind)(ctx, "", &CallbackQuery{}))
+			assert.False(IsMessageKind(c.kind)(ctx, "", nil))
+		})
+	}
+}
+
+func TestLargestPhoto(t *testing.T) {
+	msg := Message{Photo: []PhotoSize{
+		{FileId: "small", Width: 90, Height: 60},
+		{FileId: "large", Width: 900, Height: 600},
+		{FileId: "medium", Width: 320, Height: 240},
+	}}
+	require.Equal(t, "large", msg.LargestPhoto().FileId)
+	require.Nil(t, (&Message{}).LargestPhoto())
+}
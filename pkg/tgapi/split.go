@@ -0,0 +1,494 @@
+package tgapi
+
+import (
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+const (
+	// limits of message text and media caption, UTF-16 units
+	MaxMessageLength = 4096
+	MaxCaptionLength = 1024
+
+	// marks truncated text
+	Ellipsis = "…"
+)
+
+// boundary preference, higher is better
+const (
+	runeBoundary = iota
+	wordBoundary
+	lineBoundary
+	paragraphBoundary
+)
+
+type token struct {
+	text  string
+	units int // UTF-16 length
+	// cut after this token only if there is no other way, e.g. inside MarkdownV2 code block
+	sticky bool
+	// no cut after this token unless it is single one in part
+	glue bool
+}
+
+// Split cuts text into parts of at most limit UTF-16 units,
+// on paragraph, line, word or rune boundaries, in that order of preference.
+// Entities, HTML tags and MarkdownV2 spans crossing boundaries are closed
+// and continued in next part; code blocks are kept whole when possible.
+func (t Text) Split(limit int) []Text {
+	if limit <= 0 {
+		limit = MaxMessageLength
+	}
+	if utf16Len(t.Text) <= limit {
+		return []Text{t}
+	}
+	parts := t.split(limit)
+	if len(parts) == 0 {
+		// whitespace only, let API complain
+		return []Text{t}
+	}
+	return parts
+}
+
+// split is Split with no parts for whitespace only text
+func (t Text) split(limit int) []Text {
+	switch t.ParseMode {
+	case ParseModeHTML:
+		return splitHTML(t.Text, limit)
+	case ParseModeMarkdownV2:
+		return splitMarkdown(t.Text, limit)
+	}
+	return splitEntities(t, limit)
+}
+
+// Truncate cuts text to limit UTF-16 units, ending it with Ellipsis
+func (t Text) Truncate(limit int) Text {
+	if limit <= 0 {
+		limit = MaxMessageLength
+	}
+	if utf16Len(t.Text) <= limit {
+		return t
+	}
+	if limit <= utf16Len(Ellipsis) {
+		return Text{Text: Ellipsis}
+	}
+	parts := t.split(limit - utf16Len(Ellipsis))
+	if len(parts) == 0 {
+		return Text{Text: Ellipsis}
+	}
+	res := parts[0]
+	res.Text += Ellipsis
+	return res
+}
+
+// ======== Splitting ========
+
+// splitTokens returns token ranges of parts; prefix and suffix are lengths of markup
+// needed to reopen and close formatting if part starts or ends at given token
+func splitTokens(tokens []token, limit int, prefix, suffix func(i int) int) [][2]int {
+	var res [][2]int
+	start := 0
+	for {
+		for start < len(tokens) && isSpace(tokens[start]) {
+			start++
+		}
+		if start == len(tokens) {
+			return res
+		}
+		end := nextCut(tokens, start, limit-prefix(start), suffix)
+		last := end
+		for last > start && isSpace(tokens[last-1]) {
+			last--
+		}
+		if last > start {
+			res = append(res, [2]int{start, last})
+		}
+		start = end
+	}
+}
+
+// nextCut finds end of part starting at start token
+func nextCut(tokens []token, start, budget int, suffix func(i int) int) int {
+	// last fitting boundary of each kind, outside and inside sticky tokens
+	var best, bestLen [2][paragraphBoundary + 1]int
+	// longest fitting part, even if it ends with glued token
+	longest := 0
+	length := 0
+	for i := start; i < len(tokens); i++ {
+		length += tokens[i].units
+		if length+suffix(i+1) > budget {
+			break
+		}
+		if i+1 == len(tokens) {
+			return len(tokens)
+		}
+		longest = i + 1
+		if tokens[i].glue {
+			continue
+		}
+		sticky := 0
+		if tokens[i].sticky {
+			sticky = 1
+		}
+		kind := boundaryAfter(tokens, i)
+		best[sticky][kind], bestLen[sticky][kind] = i+1, length
+	}
+	// prefer better boundary unless it makes part too short,
+	// then any boundary outside sticky tokens
+	for sticky := range best {
+		for _, short := range []bool{false, true} {
+			for kind := paragraphBoundary; kind > runeBoundary; kind-- {
+				if best[sticky][kind] != 0 && (short || bestLen[sticky][kind] >= budget/2) {
+					return best[sticky][kind]
+				}
+			}
+		}
+	}
+	for sticky := range best {
+		if best[sticky][runeBoundary] != 0 {
+			return best[sticky][runeBoundary]
+		}
+	}
+	// glued tokens do not fit, cut them at limit
+	if longest != 0 {
+		return longest
+	}
+	// single token does not fit, cut it anyway
+	return start + 1
+}
+
+// boundaryAfter is kind of boundary between token i and next one,
+// defined by whitespace around it
+func boundaryAfter(tokens []token, i int) int {
+	if !isSpace(tokens[i]) && !isSpace(tokens[i+1]) {
+		return runeBoundary
+	}
+	from, to := i, i+1
+	for from > 0 && isSpace(tokens[from-1]) {
+		from--
+	}
+	for to < len(tokens)-1 && isSpace(tokens[to+1]) {
+		to++
+	}
+	lines := 0
+	for _, tok := range tokens[from : to+1] {
+		if tok.text == "\n" {
+			lines++
+		}
+	}
+	switch {
+	case lines > 1:
+		return paragraphBoundary
+	case lines == 1:
+		return lineBoundary
+	}
+	return wordBoundary
+}
+
+func isSpace(t token) bool {
+	return t.text == " " || t.text == "\n" || t.text == "\t" || t.text == "\r"
+}
+
+func noMarkup(int) int { return 0 }
+
+// ======== Entities ========
+
+func splitEntities(t Text, limit int) []Text {
+	var tokens []token
+	offsets := []int{0} // UTF-16 offset of each token
+	for _, r := range t.Text {
+		units := utf16.RuneLen(r)
+		tokens = append(tokens, token{text: string(r), units: units})
+		offsets = append(offsets, offsets[len(offsets)-1]+units)
+	}
+	var res []Text
+	for _, cut := range splitTokens(tokens, limit, noMarkup, noMarkup) {
+		var part strings.Builder
+		for _, tok := range tokens[cut[0]:cut[1]] {
+			part.WriteString(tok.text)
+		}
+		text := Text{Text: part.String(), ParseMode: t.ParseMode}
+		from, to := offsets[cut[0]], offsets[cut[1]]
+		for _, entity := range t.Entities {
+			begin, end := entity.Offset, entity.Offset+entity.Length
+			if begin < from {
+				begin = from
+			}
+			if end > to {
+				end = to
+			}
+			if begin >= end {
+				continue
+			}
+			entity.Offset, entity.Length = begin-from, end-begin
+			text.Entities = append(text.Entities, entity)
+		}
+		res = append(res, text)
+	}
+	return res
+}
+
+// ======== HTML ========
+
+func splitHTML(text string, limit int) []Text {
+	var tokens []token
+	stacks := [][]string{nil} // open tags before each token
+	for len(text) != 0 {
+		tok := htmlToken(text)
+		text = text[len(tok):]
+		tokens = append(tokens, token{text: tok, units: utf16Len(tok)})
+		stack := stacks[len(stacks)-1]
+		switch {
+		case strings.HasPrefix(tok, "</"):
+			name := tagName(tok)
+			for i := len(stack) - 1; i >= 0; i-- {
+				if tagName(stack[i]) == name {
+					stack = append(stack[:i:i], stack[i+1:]...)
+					break
+				}
+			}
+		case strings.HasPrefix(tok, "<") && !strings.HasSuffix(tok, "/>"):
+			stack = append(stack[:len(stack):len(stack)], tok)
+		}
+		stacks = append(stacks, stack)
+	}
+	prefix := func(i int) int { return utf16Len(strings.Join(stacks[i], "")) }
+	suffix := func(i int) int { return utf16Len(closeTags(stacks[i])) }
+	var res []Text
+	for _, cut := range splitTokens(tokens, limit, prefix, suffix) {
+		var part strings.Builder
+		part.WriteString(strings.Join(stacks[cut[0]], ""))
+		for _, tok := range tokens[cut[0]:cut[1]] {
+			part.WriteString(tok.text)
+		}
+		part.WriteString(closeTags(stacks[cut[1]]))
+		res = append(res, HTMLText(part.String()))
+	}
+	return res
+}
+
+// htmlToken is tag, character reference or rune at start of text
+func htmlToken(text string) string {
+	switch text[0] {
+	case '<':
+		if i := strings.IndexByte(text, '>'); i >= 0 {
+			return text[:i+1]
+		}
+	case '&':
+		if i := strings.IndexByte(text, ';'); i >= 0 && i < 10 {
+			return text[:i+1]
+		}
+	}
+	_, size := utf8.DecodeRuneInString(text)
+	return text[:size]
+}
+
+func tagName(tag string) string {
+	name := strings.TrimLeft(tag, "</")
+	if i := strings.IndexAny(name, " \t\n>"); i >= 0 {
+		name = name[:i]
+	}
+	return strings.ToLower(name)
+}
+
+func closeTags(stack []string) string {
+	var res strings.Builder
+	for i := len(stack) - 1; i >= 0; i-- {
+		res.WriteString("</" + tagName(stack[i]) + ">")
+	}
+	return res.String()
+}
+
+// ======== MarkdownV2 ========
+
+const (
+	markdownCode      = "`"
+	markdownCodeBlock = "```"
+)
+
+// markers of spans, closed by same marker
+var markdownMarkers = []string{"__", "||", "*", "_", "~", markdownCode}
+
+func splitMarkdown(text string, limit int) []Text {
+	var tokens []token
+	stacks := [][]string{nil} // open spans before each token
+	for len(text) != 0 {
+		prev := stacks[len(stacks)-1]
+		tok := markdownToken(text, prev)
+		text = text[len(tok):]
+		stack := markdownSpans(prev, tok)
+		// keep code blocks whole if possible
+		code := len(stack) != 0 && strings.HasPrefix(stack[len(stack)-1], markdownCodeBlock)
+		// no empty spans: opening marker sticks to next token, closing one to previous
+		if len(stack) < len(prev) && len(tokens) != 0 {
+			tokens[len(tokens)-1].glue = true
+		}
+		// link is kept whole if possible, but cut at any rune of it if too long
+		pieces := []string{tok}
+		if len(tok) > 1 && tok[0] == '[' {
+			pieces = markdownRunes(tok)
+		}
+		for i, piece := range pieces {
+			glue := len(stack) > len(prev) || i+1 < len(pieces)
+			tokens = append(tokens, token{text: piece, units: utf16Len(piece), sticky: code, glue: glue})
+			stacks = append(stacks, stack)
+		}
+	}
+	prefix := func(i int) int { return utf16Len(openMarkdown(stacks[i])) }
+	suffix := func(i int) int { return utf16Len(closeMarkdown(stacks[i])) }
+	var res []Text
+	for _, cut := range splitTokens(tokens, limit, prefix, suffix) {
+		var part strings.Builder
+		part.WriteString(openMarkdown(stacks[cut[0]]))
+		for _, tok := range tokens[cut[0]:cut[1]] {
+			part.WriteString(tok.text)
+		}
+		part.WriteString(closeMarkdown(stacks[cut[1]]))
+		res = append(res, MarkdownV2Text(part.String()))
+	}
+	return res
+}
+
+// markdownToken is escaped character, span marker, whole link or rune at start of text;
+// only escapes and closing marker are recognized inside code
+func markdownToken(text string, stack []string) string {
+	open := ""
+	if len(stack) != 0 {
+		open = stack[len(stack)-1]
+	}
+	switch {
+	case text[0] == '\\' && len(text) > 1:
+		_, size := utf8.DecodeRuneInString(text[1:])
+		return text[:1+size]
+	case strings.HasPrefix(open, markdownCodeBlock):
+		if strings.HasPrefix(text, markdownCodeBlock) {
+			return markdownCodeBlock
+		}
+	case open == markdownCode:
+		if strings.HasPrefix(text, markdownCode) {
+			return markdownCode
+		}
+	case strings.HasPrefix(text, markdownCodeBlock):
+		// opening fence with language
+		lang := strings.IndexFunc(text[len(markdownCodeBlock):], func(r rune) bool {
+			return !strings.ContainsRune("abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789_+-", r)
+		})
+		if lang >= 0 && text[len(markdownCodeBlock)+lang] == '\n' {
+			return text[:len(markdownCodeBlock)+lang+1]
+		}
+		return markdownCodeBlock
+	case text[0] == '[':
+		if n := markdownLink(text); n > 0 {
+			return text[:n]
+		}
+	default:
+		for _, marker := range markdownMarkers {
+			if strings.HasPrefix(text, marker) {
+				return marker
+			}
+		}
+	}
+	_, size := utf8.DecodeRuneInString(text)
+	return text[:size]
+}
+
+// markdownLink is length of [text](url) at start of text, 0 if none
+func markdownLink(text string) int {
+	closing := "]("
+	for i := 1; i < len(text); i++ {
+		switch {
+		case text[i] == '\\':
+			i++
+		case strings.HasPrefix(text[i:], closing) && closing == "](":
+			i++
+			closing = ")"
+		case text[i] == ')' && closing == ")":
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// markdownRunes splits text into runes, keeping escaped ones with backslash
+func markdownRunes(text string) []string {
+	var res []string
+	for len(text) != 0 {
+		size := 0
+		if text[0] == '\\' && len(text) > 1 {
+			size = 1
+		}
+		_, n := utf8.DecodeRuneInString(text[size:])
+		res = append(res, text[:size+n])
+		text = text[size+n:]
+	}
+	return res
+}
+
+// markdownSpans is stack of open spans after token
+func markdownSpans(stack []string, tok string) []string {
+	if len(tok) > 1 && tok[0] == '\\' {
+		return stack
+	}
+	if len(stack) != 0 {
+		switch open := stack[len(stack)-1]; {
+		case strings.HasPrefix(open, markdownCodeBlock), open == markdownCode:
+			if tok == markdownCodeBlock || tok == markdownCode {
+				return stack[: len(stack)-1 : len(stack)-1]
+			}
+			return stack
+		}
+	}
+	if strings.HasPrefix(tok, markdownCodeBlock) {
+		return append(stack[:len(stack):len(stack)], tok)
+	}
+	for _, marker := range markdownMarkers {
+		if tok != marker {
+			continue
+		}
+		for i := len(stack) - 1; i >= 0; i-- {
+			if stack[i] == marker {
+				return append(stack[:i:i], stack[i+1:]...)
+			}
+		}
+		return append(stack[:len(stack):len(stack)], tok)
+	}
+	return stack
+}
+
+func openMarkdown(stack []string) string {
+	return joinMarkers(stack)
+}
+
+func closeMarkdown(stack []string) string {
+	var markers []string
+	for i := len(stack) - 1; i >= 0; i-- {
+		if strings.HasPrefix(stack[i], markdownCodeBlock) {
+			markers = append(markers, markdownCodeBlock)
+		} else {
+			markers = append(markers, stack[i])
+		}
+	}
+	return joinMarkers(markers)
+}
+
+// joinMarkers separates adjacent italic and underline with ignored \r,
+// or "___" is ambiguous
+func joinMarkers(markers []string) string {
+	var res strings.Builder
+	for i, marker := range markers {
+		if i > 0 && strings.HasSuffix(markers[i-1], "_") && strings.HasPrefix(marker, "_") {
+			res.WriteString("\r")
+		}
+		res.WriteString(marker)
+	}
+	return res.String()
+}
+
+func utf16Len(s string) int {
+	var res int
+	for _, r := range s {
+		res += utf16.RuneLen(r)
+	}
+	return res
+}
@@ -0,0 +1,240 @@
+package tgapi
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSplit(t *testing.T) {
+	testCases := []struct {
+		desc  string
+		text  Text
+		limit int
+		parts []Text
+	}{
+		{
+			desc:  "short",
+			text:  PlainText("hello world"),
+			limit: 20,
+			parts: []Text{PlainText("hello world")},
+		},
+		{
+			desc:  "paragraph",
+			text:  PlainText("first line\nsecond\n\nthird paragraph"),
+			limit: 25,
+			parts: []Text{PlainText("first line\nsecond"), PlainText("third paragraph")},
+		},
+		{
+			desc:  "early paragraph",
+			text:  PlainText("a\n\nfirst line\nsecond line"),
+			limit: 16,
+			parts: []Text{PlainText("a\n\nfirst line"), PlainText("second line")},
+		},
+		{
+			desc:  "words",
+			text:  PlainText("one two three four"),
+			limit: 9,
+			parts: []Text{PlainText("one two"), PlainText("three"), PlainText("four")},
+		},
+		{
+			desc:  "runes",
+			text:  PlainText("абвгдеёж"),
+			limit: 3,
+			parts: []Text{PlainText("абв"), PlainText("где"), PlainText("ёж")},
+		},
+		{
+			desc:  "surrogates",
+			text:  PlainText("😀😀😀"),
+			limit: 3,
+			parts: []Text{PlainText("😀"), PlainText("😀"), PlainText("😀")},
+		},
+		{
+			desc: "entities",
+			text: Text{Text: "plain bold text", Entities: []MessageEntity{
+				{Type: "bold", Offset: 6, Length: 9},
+				{Type: "italic", Offset: 0, Length: 5},
+			}},
+			limit: 10,
+			parts: []Text{
+				{Text: "plain bold", Entities: []MessageEntity{
+					{Type: "bold", Offset: 6, Length: 4},
+					{Type: "italic", Offset: 0, Length: 5},
+				}},
+				{Text: "text", Entities: []MessageEntity{
+					{Type: "bold", Offset: 0, Length: 4},
+				}},
+			},
+		},
+		{
+			desc:  "html",
+			text:  HTMLText(`<b>one <a href="x">two</a> three</b> &amp;&amp;`),
+			limit: 30,
+			parts: []Text{
+				HTMLText(`<b>one <a href="x">two</a></b>`),
+				HTMLText(`<b>three</b> &amp;&amp;`),
+			},
+		},
+		{
+			desc:  "html reopen",
+			text:  HTMLText(`<i>aaaa bbbb</i>`),
+			limit: 11,
+			parts: []Text{HTMLText(`<i>aaaa</i>`), HTMLText(`<i>bbbb</i>`)},
+		},
+		{
+			desc:  "markdown escapes",
+			text:  MarkdownV2Text(`aaa\.\.\.`),
+			limit: 4,
+			parts: []Text{MarkdownV2Text(`aaa`), MarkdownV2Text(`\.\.`), MarkdownV2Text(`\.`)},
+		},
+		{
+			desc:  "markdown span",
+			text:  MarkdownV2Text("*bold bold bold bold bold bold bold bold bold*"),
+			limit: 40,
+			parts: []Text{
+				MarkdownV2Text("*bold bold bold bold bold bold bold*"),
+				MarkdownV2Text("*bold bold*"),
+			},
+		},
+		{
+			desc:  "markdown nested spans",
+			text:  MarkdownV2Text("_a __b ||c d|| e__ f_"),
+			limit: 14,
+			parts: []Text{
+				// \r separates italic and underline markers
+				MarkdownV2Text("_a __b__\r_"),
+				MarkdownV2Text("_\r__||c||__\r_"),
+				MarkdownV2Text("_\r__||d||__\r_"),
+				MarkdownV2Text("_\r__e__ f_"),
+			},
+		},
+		{
+			desc:  "markdown inline code",
+			text:  MarkdownV2Text("`a*b c_d`"),
+			limit: 6,
+			parts: []Text{MarkdownV2Text("`a*b`"), MarkdownV2Text("`c_d`")},
+		},
+		{
+			desc:  "markdown link",
+			text:  MarkdownV2Text(`x [a b c](http://x.y/\)) y`),
+			limit: 24,
+			parts: []Text{MarkdownV2Text(`x [a b c](http://x.y/\))`), MarkdownV2Text("y")},
+		},
+		{
+			desc:  "markdown long link",
+			text:  MarkdownV2Text(`[abc\.def\.ghi](http://x.y)`),
+			limit: 10,
+			parts: []Text{MarkdownV2Text(`[abc\.def`), MarkdownV2Text(`\.ghi](htt`), MarkdownV2Text("p://x.y)")},
+		},
+		{
+			desc:  "markdown escaped marker",
+			text:  MarkdownV2Text(`\* aa \*`),
+			limit: 5,
+			parts: []Text{MarkdownV2Text(`\* aa`), MarkdownV2Text(`\*`)},
+		},
+		{
+			desc:  "markdown code block language",
+			text:  MarkdownV2Text("```go\nline one\nline two```"),
+			limit: 20,
+			parts: []Text{MarkdownV2Text("```go\nline one```"), MarkdownV2Text("```go\nline two```")},
+		},
+		{
+			desc:  "markdown code block",
+			text:  MarkdownV2Text("text\n```\ncode\ncode\n```\ntext"),
+			limit: 20,
+			parts: []Text{MarkdownV2Text("text"), MarkdownV2Text("```\ncode\ncode\n```"), MarkdownV2Text("text")},
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			parts := c.text.Split(c.limit)
+			assert.Equal(c.parts, parts)
+			for _, part := range parts {
+				assert.LessOrEqual(utf16Len(part.Text), c.limit, part.Text)
+			}
+		})
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		text   Text
+		limit  int
+		result Text
+	}{
+		{
+			desc:   "short",
+			text:   PlainText("hello"),
+			limit:  5,
+			result: PlainText("hello"),
+		},
+		{
+			desc:   "long",
+			text:   PlainText("hello world"),
+			limit:  8,
+			result: PlainText("hello…"),
+		},
+		{
+			desc:   "entity",
+			text:   Text{Text: "hello world", Entities: []MessageEntity{{Type: "bold", Offset: 3, Length: 8}}},
+			limit:  8,
+			result: Text{Text: "hello…", Entities: []MessageEntity{{Type: "bold", Offset: 3, Length: 2}}},
+		},
+		{
+			desc:   "whitespace",
+			text:   PlainText(strings.Repeat(" ", 50)),
+			limit:  10,
+			result: PlainText(Ellipsis),
+		},
+		{
+			desc:   "markdown",
+			text:   MarkdownV2Text("*hello world*"),
+			limit:  10,
+			result: MarkdownV2Text("*hello*…"),
+		},
+		{
+			desc:   "html",
+			text:   HTMLText("<b>hello world</b>"),
+			limit:  15,
+			result: HTMLText("<b>hello</b>…"),
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			res := c.text.Truncate(c.limit)
+			require.Equal(t, c.result, res)
+			require.LessOrEqual(t, utf16Len(res.Text), c.limit)
+		})
+	}
+}
+
+func TestSendLongText(t *testing.T) {
+	assert := require.New(t)
+	long := strings.Repeat("a", MaxMessageLength) + "\n\n" + strings.Repeat("b", 10)
+	var sent []string
+	client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(err)
+		var params SendParams
+		assert.NoError(json.Unmarshal(body, &params))
+		sent = append(sent, params.Text)
+		rw.Write([]byte(`{"ok":true,"result":{"message_id":42}}`))
+	})
+
+	id, err := client.CreateInputKeyboard(context.Background(), ToChat(1), PlainText(long), InlineKeyboard{})
+	assert.NoError(err)
+	assert.Equal(uint64(42), id)
+	assert.Equal([]string{strings.Repeat("a", MaxMessageLength), strings.Repeat("b", 10)}, sent)
+
+	sent = nil
+	_, err = client.EditMessage(context.Background(), ToChat(1), PlainText(long), 42)
+	assert.NoError(err)
+	assert.Equal([]string{strings.Repeat("a", MaxMessageLength-1) + Ellipsis}, sent)
+}
@@ -1,6 +1,10 @@
 package tgapi
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"io"
+)
 
 // ======== Incoming updates ========
 
@@ -10,79 +14,328 @@ type User struct {
 	LanguageCode string `json:"language_code"`
 }
 
+// BotInfo is bot's own identity returned by getMe
+type BotInfo struct {
+	Id                      uint64 `json:"id"`
+	IsBot                   bool   `json:"is_bot"`
+	FirstName               string `json:"first_name"`
+	Username                string `json:"username"`
+	CanJoinGroups           bool   `json:"can_join_groups"`
+	CanReadAllGroupMessages bool   `json:"can_read_all_group_messages"`
+	SupportsInlineQueries   bool   `json:"supports_inline_queries"`
+}
+
+const (
+	PrivateChat    = "private"
+	GroupChat      = "group"
+	SupergroupChat = "supergroup"
+	ChannelChat    = "channel"
+)
+
+// groups and channels have negative ids
 type Chat struct {
-	Id        uint64 `json:"id"`
+	Id        int64  `json:"id"`
 	Type      string `json:"type"`
+	Title     string `json:"title,omitempty"`
+	Username  string `json:"username,omitempty"`
 	FirstName string `json:"first_name"`
+	IsForum   bool   `json:"is_forum,omitempty"` // supergroup with topics
+}
+
+func (c Chat) IsPrivate() bool { return c.Type == PrivateChat }
+func (c Chat) IsGroup() bool   { return c.Type == GroupChat || c.Type == SupergroupChat }
+
+// Recipient is where message is sent to
+type Recipient struct {
+	ChatId   int64
+	ThreadId int64  // forum topic, 0 for general
+	ReplyTo  uint64 // message to reply to, 0 for none
 }
 
+func ToChat(chat int64) Recipient { return Recipient{ChatId: chat} }
+
+// Reply makes new messages replies to msgId, e.g. for Selective markup
+func (r Recipient) Reply(msgId uint64) Recipient {
+	r.ReplyTo = msgId
+	return r
+}
+
+// ToUser is private chat with user
+func ToUser(user User) Recipient { return Recipient{ChatId: int64(user.Id)} }
+
 type Message struct {
-	MessageId uint64 `json:"message_id"`
-	From      User   `json:"from"`
-	Chat      Chat   `json:"chat"`
-	Date      uint64 `json:"date"`
-	Text      string `json:"text"`
+	MessageId uint64          `json:"message_id"`
+	From      User            `json:"from"`
+	Chat      Chat            `json:"chat"`
+	Date      uint64          `json:"date"`
+	Text      string          `json:"text"`
+	Entities  []MessageEntity `json:"entities,omitempty"`
+
+	// forum topic
+	MessageThreadId int64 `json:"message_thread_id,omitempty"`
+	IsTopicMessage  bool  `json:"is_topic_message,omitempty"`
+
+	// media, caption is text for them
+	Photo           []PhotoSize     `json:"photo,omitempty"` // all sizes available
+	Document        *Document       `json:"document,omitempty"`
+	Audio           *Audio          `json:"audio,omitempty"`
+	Voice           *Voice          `json:"voice,omitempty"`
+	Video           *Video          `json:"video,omitempty"`
+	Sticker         *Sticker        `json:"sticker,omitempty"`
+	Caption         string          `json:"caption,omitempty"`
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+
+	Contact  *Contact  `json:"contact,omitempty"`
+	Location *Location `json:"location,omitempty"`
+	Poll     *Poll     `json:"poll,omitempty"`
 
 	UUID string `json:"-"`
 }
 
 func (m *Message) User() User                                             { return m.From }
+func (m *Message) Origin() Chat                                           { return m.Chat }
 func (m *Message) Message() interface{}                                   { return m }
 func (m *Message) PreProcess(ctx context.Context, client TGClient) error  { return nil }
 func (m *Message) PostProcess(ctx context.Context, client TGClient) error { return nil }
 
+// Recipient is chat and topic to reply to
+func (m *Message) Recipient() Recipient {
+	res := Recipient{ChatId: m.Chat.Id}
+	if m.IsTopicMessage {
+		res.ThreadId = m.MessageThreadId
+	}
+	return res
+}
+
+// EditedMessage is new version of message sent before
+type EditedMessage Message
+
+func (m *EditedMessage) User() User                                             { return m.From }
+func (m *EditedMessage) Origin() Chat                                           { return m.Chat }
+func (m *EditedMessage) Message() interface{}                                   { return m }
+func (m *EditedMessage) PreProcess(ctx context.Context, client TGClient) error  { return nil }
+func (m *EditedMessage) PostProcess(ctx context.Context, client TGClient) error { return nil }
+
+// bold, links, commands etc, offsets and lengths are in UTF-16 units
+type MessageEntity struct {
+	Type     string `json:"type"`
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	URL      string `json:"url,omitempty"`      // text_link
+	User     *User  `json:"user,omitempty"`     // text_mention
+	Language string `json:"language,omitempty"` // pre
+}
+
+type PhotoSize struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+type Document struct {
+	FileId       string     `json:"file_id"`
+	FileUniqueId string     `json:"file_unique_id"`
+	Thumbnail    *PhotoSize `json:"thumbnail,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+}
+
+type Audio struct {
+	FileId       string     `json:"file_id"`
+	FileUniqueId string     `json:"file_unique_id"`
+	Duration     int        `json:"duration"`
+	Performer    string     `json:"performer,omitempty"`
+	Title        string     `json:"title,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+	Thumbnail    *PhotoSize `json:"thumbnail,omitempty"`
+}
+
+type Voice struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Duration     int    `json:"duration"`
+	MimeType     string `json:"mime_type,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+type Video struct {
+	FileId       string     `json:"file_id"`
+	FileUniqueId string     `json:"file_unique_id"`
+	Width        int        `json:"width"`
+	Height       int        `json:"height"`
+	Duration     int        `json:"duration"`
+	Thumbnail    *PhotoSize `json:"thumbnail,omitempty"`
+	FileName     string     `json:"file_name,omitempty"`
+	MimeType     string     `json:"mime_type,omitempty"`
+	FileSize     int64      `json:"file_size,omitempty"`
+}
+
+type Sticker struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	Type         string `json:"type"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	IsAnimated   bool   `json:"is_animated"`
+	IsVideo      bool   `json:"is_video"`
+	Emoji        string `json:"emoji,omitempty"`
+	SetName      string `json:"set_name,omitempty"`
+	FileSize     int64  `json:"file_size,omitempty"`
+}
+
+type Contact struct {
+	PhoneNumber string `json:"phone_number"`
+	FirstName   string `json:"first_name"`
+	LastName    string `json:"last_name,omitempty"`
+	UserId      uint64 `json:"user_id,omitempty"`
+	VCard       string `json:"vcard,omitempty"`
+}
+
+type Location struct {
+	Longitude          float64 `json:"longitude"`
+	Latitude           float64 `json:"latitude"`
+	HorizontalAccuracy float64 `json:"horizontal_accuracy,omitempty"`
+	LivePeriod         int     `json:"live_period,omitempty"`
+}
+
 type CallbackQuery struct {
-	Id           string `json:"id"`
-	From         User   `json:"from"`
-	ChatInstance string `json:"chat_instance"`
-	Data         string `json:"data"`
+	Id           string   `json:"id"`
+	From         User     `json:"from"`
+	Source       *Message `json:"message,omitempty"` // with the button, if not too old
+	ChatInstance string   `json:"chat_instance"`
+	Data         string   `json:"data"`
 
 	UUID string `json:"-"`
+	// sent after processing, empty if not set
+	answer AnswerCallback
 }
 
-func (m *CallbackQuery) User() User           { return m.From }
-func (m *CallbackQuery) Message() interface{} { return m }
-func (m *CallbackQuery) PreProcess(ctx context.Context, client TGClient) error {
-	return client.AnswerCallback(ctx, m.Id)
+func (m *CallbackQuery) User() User { return m.From }
+
+// Origin is chat of message with the button, or private chat with user
+func (m *CallbackQuery) Origin() Chat {
+	if m.Source != nil {
+		return m.Source.Chat
+	}
+	return Chat{Id: int64(m.From.Id), Type: PrivateChat, FirstName: m.From.FirstName}
 }
-func (m *CallbackQuery) PostProcess(ctx context.Context, client TGClient) error { return nil }
 
-type Update struct {
-	UpdateId      uint64         `json:"update_id"`
-	Message       *Message       `json:"message"`
-	CallbackQuery *CallbackQuery `json:"callback_query"`
+func (m *CallbackQuery) Recipient() Recipient {
+	if m.Source != nil {
+		return m.Source.Recipient()
+	}
+	return ToUser(m.From)
 }
 
-type UpdateResponse struct {
-	Result []Update `json:"result"`
-	Ok     bool     `json:"ok"`
+// SetAnswer sets toast, alert or URL shown on button press, last one wins
+func (m *CallbackQuery) SetAnswer(answer AnswerCallback) { m.answer = answer }
+
+func (m *CallbackQuery) Message() interface{}                                  { return m }
+func (m *CallbackQuery) PreProcess(ctx context.Context, client TGClient) error { return nil }
+
+// PostProcess answers query, client shows progress until then
+func (m *CallbackQuery) PostProcess(ctx context.Context, client TGClient) error {
+	answer := m.answer
+	answer.CallbackQueryId = m.Id
+	return client.AnswerCallback(ctx, answer)
 }
 
-type SendResponse struct {
-	Result Message `json:"result"`
-	Ok     bool    `json:"ok"`
+type Update struct {
+	UpdateId           uint64              `json:"update_id"`
+	Message            *Message            `json:"message"`
+	CallbackQuery      *CallbackQuery      `json:"callback_query"`
+	InlineQuery        *InlineQuery        `json:"inline_query"`
+	ChosenInlineResult *ChosenInlineResult `json:"chosen_inline_result"`
+	EditedMessage      *EditedMessage      `json:"edited_message"`
+	MyChatMember       *ChatMemberUpdated  `json:"my_chat_member"`
+	ChatMember         *ChatMemberUpdated  `json:"chat_member"` // must be in allowed updates
+	Poll               *Poll               `json:"poll"`
+	PollAnswer         *PollAnswer         `json:"poll_answer"`
 }
 
 // ======== Outgoing requests ========
 
+const (
+	ParseModeHTML       = "HTML"
+	ParseModeMarkdownV2 = "MarkdownV2"
+)
+
+// Text is message text with formatting:
+// either markup of ParseMode, or explicit Entities
+type Text struct {
+	Text      string
+	ParseMode string
+	Entities  []MessageEntity
+}
+
+func PlainText(text string) Text      { return Text{Text: text} }
+func HTMLText(text string) Text       { return Text{Text: text, ParseMode: ParseModeHTML} }
+func MarkdownV2Text(text string) Text { return Text{Text: text, ParseMode: ParseModeMarkdownV2} }
+
 // base outgoing message
 type SendParams struct {
-	ChatId    uint64 `json:"chat_id"`
-	Text      string `json:"text"`
-	MessageId uint64 `json:"message_id,omitempty"`
+	ChatId          int64            `json:"chat_id"`
+	MessageThreadId int64            `json:"message_thread_id,omitempty"`
+	Text            string           `json:"text"`
+	ParseMode       string           `json:"parse_mode,omitempty"`
+	Entities        []MessageEntity  `json:"entities,omitempty"`
+	MessageId       uint64           `json:"message_id,omitempty"`
+	ReplyParameters *ReplyParameters `json:"reply_parameters,omitempty"`
+}
+
+type ReplyParameters struct {
+	MessageId uint64 `json:"message_id"`
+	// send anyway if message is deleted
+	AllowSendingWithoutReply bool `json:"allow_sending_without_reply,omitempty"`
 }
 
-// keyboard with answers
+func makeSendParams(chat Recipient, text Text, msgId uint64) SendParams {
+	res := SendParams{
+		ChatId:    chat.ChatId,
+		Text:      text.Text,
+		ParseMode: text.ParseMode,
+		Entities:  text.Entities,
+		MessageId: msgId, // 0 will be omitted
+	}
+	if msgId == 0 {
+		// edited message stays where it is
+		res.MessageThreadId = chat.ThreadId
+		if chat.ReplyTo != 0 {
+			res.ReplyParameters = &ReplyParameters{MessageId: chat.ReplyTo, AllowSendingWithoutReply: true}
+		}
+	}
+	return res
+}
+
+// ReplyMarkup is one of InlineKeyboard, AnswerKeyboard, DropKeyboard or ForceReply
+type ReplyMarkup interface {
+	replyMarkup()
+}
+
+// keyboard with answers, button text is sent as message
 type AnswerKeyboardButton struct {
-	Text string `json:"text"`
+	Text            string `json:"text"`
+	RequestContact  bool   `json:"request_contact,omitempty"`  // private chats only
+	RequestLocation bool   `json:"request_location,omitempty"` // private chats only
 }
 
 type AnswerKeyboard struct {
-	Keyboard [][]AnswerKeyboardButton `json:"keyboard"`
-	OneTime  bool                     `json:"one_time"`
-	Resize   bool                     `json:"resize"`
+	Keyboard     [][]AnswerKeyboardButton `json:"keyboard"`
+	IsPersistent bool                     `json:"is_persistent,omitempty"`     // shown even when system keyboard is
+	Resize       bool                     `json:"resize_keyboard,omitempty"`   // fit to buttons
+	OneTime      bool                     `json:"one_time_keyboard,omitempty"` // hide after use
+	Placeholder  string                   `json:"input_field_placeholder,omitempty"`
+	// only for mentioned users and author of replied message
+	Selective bool `json:"selective,omitempty"`
 }
 
+func (AnswerKeyboard) replyMarkup() {}
+
 type SendAnswerKeyboard struct {
 	SendParams
 	ReplyMarkup AnswerKeyboard `json:"reply_markup"`
@@ -98,6 +351,8 @@ type InlineKeyboard struct {
 	InlineKeyboard [][]InlineKeyboardButton `json:"inline_keyboard"`
 }
 
+func (InlineKeyboard) replyMarkup() {}
+
 type SendInlineKeyboard struct {
 	SendParams
 	ReplyMarkup InlineKeyboard `json:"reply_markup"`
@@ -106,26 +361,108 @@ type SendInlineKeyboard struct {
 // drop keyboard
 type DropKeyboard struct {
 	RemoveKeyboard bool `json:"remove_keyboard"`
+	Selective      bool `json:"selective,omitempty"`
 }
 
+func (DropKeyboard) replyMarkup() {}
+
 type SendDropKeyboard struct {
 	SendParams
 	ReplyMarkup DropKeyboard `json:"reply_markup"`
 }
 
+// show reply interface as if user selected message and tapped "Reply"
+type ForceReply struct {
+	Placeholder string `json:"input_field_placeholder,omitempty"`
+	Selective   bool   `json:"selective,omitempty"`
+}
+
+func (ForceReply) replyMarkup() {}
+
+func (r ForceReply) MarshalJSON() ([]byte, error) {
+	type reply ForceReply
+	return json.Marshal(struct {
+		ForceReply bool `json:"force_reply"`
+		reply
+	}{true, reply(r)})
+}
+
+type SendForceReply struct {
+	SendParams
+	ReplyMarkup ForceReply `json:"reply_markup"`
+}
+
+// files: photo, document, audio, voice
+type InputFile struct {
+	// file_id of file already on telegram servers, or HTTP URL
+	FileId string
+	// or file to upload
+	Name        string
+	ContentType string
+	Reader      io.Reader
+}
+
+// file is set in field depending on command
+type SendMedia struct {
+	ChatId          int64  `json:"chat_id"`
+	MessageThreadId int64  `json:"message_thread_id,omitempty"`
+	Photo           string `json:"photo,omitempty"`
+	Document        string `json:"document,omitempty"`
+	Audio           string `json:"audio,omitempty"`
+	Voice           string `json:"voice,omitempty"`
+	Caption         string `json:"caption,omitempty"`
+	ParseMode       string `json:"parse_mode,omitempty"`
+	// entities of caption
+	CaptionEntities []MessageEntity `json:"caption_entities,omitempty"`
+	ReplyMarkup     ReplyMarkup     `json:"reply_markup,omitempty"`
+}
+
+// makeSendMedia truncates long caption, there is no second caption to split to
+func makeSendMedia(chat Recipient, caption Text, markup ReplyMarkup) SendMedia {
+	caption = caption.Truncate(MaxCaptionLength)
+	return SendMedia{
+		ChatId:          chat.ChatId,
+		MessageThreadId: chat.ThreadId,
+		Caption:         caption.Text,
+		ParseMode:       caption.ParseMode,
+		CaptionEntities: caption.Entities,
+		ReplyMarkup:     markup,
+	}
+}
+
+// file download
+type GetFile struct {
+	FileId string `json:"file_id"`
+}
+
+type File struct {
+	FileId       string `json:"file_id"`
+	FileUniqueId string `json:"file_unique_id"`
+	FileSize     int64  `json:"file_size,omitempty"`
+	FilePath     string `json:"file_path,omitempty"` // download path, valid for an hour
+}
+
 // set webhook
 type SetWebhook struct {
-	URL                string `json:"url"`
-	Certificate        string `json:"certificate"`
-	DropPendingUpdates bool   `json:"drop_pending_updates"`
+	URL string `json:"url"`
+	// PEM contents of self-signed certificate, uploaded as a file
+	Certificate        string `json:"-"`
+	DropPendingUpdates bool   `json:"drop_pending_updates,omitempty"`
+	SecretToken        string `json:"secret_token,omitempty"`
 }
 
 // get updates
 type GetUpdates struct {
-	Offset uint64 `json:"offset"`
+	Offset         uint64   `json:"offset"`
+	Limit          int      `json:"limit,omitempty"`
+	Timeout        int      `json:"timeout,omitempty"` // long polling, seconds
+	AllowedUpdates []string `json:"allowed_updates,omitempty"`
 }
 
 type AnswerCallback struct {
 	CallbackQueryId string `json:"callback_query_id"`
-	Text            string `json:"text"`
+	Text            string `json:"text,omitempty"`
+	ShowAlert       bool   `json:"show_alert,omitempty"` // dialog instead of toast
+	URL             string `json:"url,omitempty"`        // game or t.me/bot?start= link
+	CacheTime       int    `json:"cache_time,omitempty"` // seconds
 }
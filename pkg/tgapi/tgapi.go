@@ -2,174 +2,527 @@ package tgapi
 
 import (
 	"context"
+	"io"
 	"net/http"
 	"net/url"
 	"path"
+	"strings"
 	"time"
 
-	"github.com/baldisbk/tgbot/pkg/httputils"
+	"github.com/jonboulle/clockwork"
 	"golang.org/x/xerrors"
+
+	"github.com/baldisbk/tgbot/pkg/httputils"
 )
 
 // ======== Client ========
 
-const defaultConnectTimeout = 10 * time.Second
+const (
+	defaultConnectTimeout  = 10 * time.Second
+	defaultMaxDownloadSize = 20 << 20 // getFile limit of Bot API
+	minDownloadRate        = 64 << 10 // bytes per second, sizes download timeout
+)
+
+// always hidden from logs
+var secretFields = []string{"secret_token"}
 
 type tgClient struct {
 	httputils.BaseClient
-	offset uint64
+	offset  uint64
+	limiter *limiter
+	botInfo BotInfo
+
+	filePath        string
+	maxDownloadSize int64
 }
 
-func makeCmd(address, token string) (string, error) {
+func makePath(address string, elem ...string) (string, error) {
 	u, err := url.Parse(address)
 	if err != nil {
 		return "", err
 	}
-	u.Path = path.Join(u.Path, "bot"+token)
+	u.Path = path.Join(append([]string{u.Path}, elem...)...)
 	return u.String() + "/", nil
 }
 
-func NewClient(ctx context.Context, cfg Config) (*tgClient, error) {
+func makeCmd(address, token string) (string, error) {
+	return makePath(address, "bot"+token)
+}
+
+func makeFilePath(address, token string) (string, error) {
+	return makePath(address, "file", "bot"+token)
+}
+
+// Request is BaseClient.Request with Bot API errors parsed
+func (c *tgClient) Request(ctx context.Context, httpmethod, apimethod string, input interface{}, output interface{}) error {
+	return apiError(c.BaseClient.Request(ctx, httpmethod, apimethod, input, output))
+}
+
+// RequestForm is BaseClient.RequestForm with Bot API errors parsed
+func (c *tgClient) RequestForm(ctx context.Context, httpmethod, apimethod string, input interface{}, files map[string]httputils.FormFile, output interface{}) error {
+	return apiError(c.BaseClient.RequestForm(ctx, httpmethod, apimethod, input, files, output))
+}
+
+// send is Call for message to chat, queued by rate limiter
+func (c *tgClient) send(ctx context.Context, chat int64, apimethod string, input interface{}, output interface{}) error {
+	if err := c.limiter.wait(ctx, chat); err != nil {
+		return xerrors.Errorf("rate limit: %w", err)
+	}
+	return c.call(ctx, apimethod, input, output)
+}
+
+// sendForm is send with files as multipart form
+func (c *tgClient) sendForm(ctx context.Context, chat int64, apimethod string, input interface{}, files map[string]httputils.FormFile, output interface{}) error {
+	if err := c.limiter.wait(ctx, chat); err != nil {
+		return xerrors.Errorf("rate limit: %w", err)
+	}
+	return c.callForm(ctx, apimethod, input, files, output)
+}
+
+// NewClient makes client with middlewares from config, then extra ones
+func NewClient(ctx context.Context, cfg Config, middlewares ...httputils.Middleware) (*tgClient, error) {
 	timeout := cfg.Timeout
 	if timeout == 0 {
 		timeout = defaultConnectTimeout
 	}
+	transport, err := cfg.Transport.Transport()
+	if err != nil {
+		return nil, xerrors.Errorf("transport: %w", err)
+	}
+	redact := cfg.Redact
+	redact.Fields = append(redact.Fields, secretFields...)
 	cli := &tgClient{
 		BaseClient: httputils.BaseClient{
 			Client: &http.Client{
-				Timeout: timeout,
+				Timeout:   timeout,
+				Transport: transport,
 			},
+			Redactor:    httputils.NewRedactor(redact, cfg.Token),
+			Middlewares: append(cfg.Middleware.Stack(retryAfter), middlewares...),
 		},
+		limiter:         newLimiter(cfg.RateLimit, clockwork.NewRealClock()),
+		maxDownloadSize: cfg.MaxDownloadSize,
+	}
+	if cli.maxDownloadSize == 0 {
+		cli.maxDownloadSize = defaultMaxDownloadSize
 	}
 	path, err := makeCmd(cfg.Address, cfg.Token)
 	if err != nil {
 		return nil, xerrors.Errorf("make url: %w", err)
 	}
 	cli.Path = path
+	if cli.filePath, err = makeFilePath(cfg.Address, cfg.Token); err != nil {
+		return nil, xerrors.Errorf("make file url: %w", err)
+	}
 	if err := cli.Test(ctx); err != nil {
 		return nil, xerrors.Errorf("test: %w", err)
 	}
 	return cli, nil
 }
 
+// Test checks token and remembers bot identity
 func (c *tgClient) Test(ctx context.Context) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.Path+TestCmd, nil)
-	if err != nil {
-		return xerrors.Errorf("make req: %w", err)
-	}
-	_, err = c.Client.Do(req)
+	info, err := c.GetMe(ctx)
 	if err != nil {
-		return xerrors.Errorf("request: %w", err)
+		if xerrors.Is(err, UnauthorizedError) || xerrors.Is(err, NotFoundError) {
+			return xerrors.Errorf("%w (%v)", InvalidTokenError, err)
+		}
+		return err
 	}
+	c.botInfo = info
 	return nil
 }
 
-func (c *tgClient) GetUpdates(ctx context.Context, offset uint64) ([]Update, uint64, error) {
-	var res UpdateResponse
-	err := c.Request(ctx, http.MethodGet, ReceiveCmd, GetUpdates{Offset: offset}, &res)
+func (c *tgClient) GetMe(ctx context.Context) (BotInfo, error) {
+	var res BotInfo
+	if err := c.Call(ctx, TestCmd, nil, &res); err != nil {
+		return BotInfo{}, err
+	}
+	if res.Id == 0 {
+		return BotInfo{}, xerrors.Errorf("%s: unexpected response", TestCmd)
+	}
+	return res, nil
+}
+
+func (c *tgClient) BotInfo() BotInfo {
+	return c.botInfo
+}
+
+func (c *tgClient) GetUpdates(ctx context.Context, params GetUpdates) ([]Update, uint64, error) {
+	var rsp Response
+	client := c.BaseClient
+	if params.Timeout > 0 {
+		// long poll must not be cut off by client timeout
+		httpClient := *c.Client
+		httpClient.Timeout += time.Duration(params.Timeout) * time.Second
+		client.Client = &httpClient
+	}
+	err := apiError(client.Request(ctx, http.MethodGet, ReceiveCmd, params, &rsp))
 	if err != nil {
 		return nil, 0, xerrors.Errorf("request: %w", err)
 	}
-	for _, r := range res.Result {
+	var res []Update
+	if err := rsp.decode(ReceiveCmd, &res); err != nil {
+		return nil, 0, err
+	}
+	offset := params.Offset
+	for _, r := range res {
 		if offset <= r.UpdateId {
 			offset = r.UpdateId + 1
 		}
-		hash(r)
+		Hash(r)
 	}
-	return res.Result, offset, nil
+	return res, offset, nil
 }
 
-func (c *tgClient) EditMessage(ctx context.Context, chat uint64, text string, msgId uint64) (uint64, error) {
-	var msg SendResponse
+func (c *tgClient) EditMessage(ctx context.Context, chat Recipient, text Text, msgId uint64) (uint64, error) {
+	var msg Message
 	var cmd = SendCmd
 	if msgId != 0 {
 		cmd = EditCmd
 	}
-	err := c.Request(ctx,
-		http.MethodPost, cmd,
-		SendParams{
-			ChatId:    chat,
-			Text:      text,
-			MessageId: msgId, // 0 will be omitted
-		}, &msg)
+	text, err := c.sendText(ctx, chat, text, msgId)
 	if err != nil {
 		return 0, err
 	}
-	return msg.Result.MessageId, nil
+	err = c.send(ctx,
+		chat.ChatId, cmd,
+		makeSendParams(chat, text, msgId), &msg)
+	if err != nil {
+		return 0, err
+	}
+	return msg.MessageId, nil
+}
+
+// sendText sends all parts of long text but last one, which is returned to send with markup;
+// edited text is truncated as only one message can be replaced
+func (c *tgClient) sendText(ctx context.Context, chat Recipient, text Text, msgId uint64) (Text, error) {
+	if msgId != 0 {
+		return text.Truncate(MaxMessageLength), nil
+	}
+	parts := text.Split(MaxMessageLength)
+	for _, part := range parts[:len(parts)-1] {
+		if err := c.send(ctx, chat.ChatId, SendCmd, makeSendParams(chat, part, 0), nil); err != nil {
+			return Text{}, xerrors.Errorf("send part: %w", err)
+		}
+	}
+	return parts[len(parts)-1], nil
 }
 
-func (c *tgClient) SendMessage(ctx context.Context, chat uint64, text string) (uint64, error) {
+func (c *tgClient) SendMessage(ctx context.Context, chat Recipient, text Text) (uint64, error) {
 	return c.EditMessage(ctx, chat, text, 0)
 }
 
-func (c *tgClient) AnswerCallback(ctx context.Context, callbackId string) error {
-	return c.Request(ctx,
-		http.MethodPost, AnswerCmd,
-		AnswerCallback{
-			CallbackQueryId: callbackId,
-		}, nil)
+func (c *tgClient) AnswerCallback(ctx context.Context, answer AnswerCallback) error {
+	return c.Call(ctx, AnswerCmd, answer, nil)
 }
 
-func (c *tgClient) EditAnswerKeyboard(ctx context.Context, chat uint64, text string, msgId uint64, keyboard AnswerKeyboard) (uint64, error) {
-	var msg SendResponse
+func (c *tgClient) EditAnswerKeyboard(ctx context.Context, chat Recipient, text Text, msgId uint64, keyboard AnswerKeyboard) (uint64, error) {
+	var msg Message
 	var cmd = SendCmd
 	if msgId != 0 {
 		cmd = EditCmd
 	}
-	err := c.Request(ctx,
-		http.MethodPost, cmd,
+	text, err := c.sendText(ctx, chat, text, msgId)
+	if err != nil {
+		return 0, err
+	}
+	err = c.send(ctx,
+		chat.ChatId, cmd,
 		SendAnswerKeyboard{
-			SendParams: SendParams{
-				ChatId:    chat,
-				Text:      text,
-				MessageId: msgId,
-			},
+			SendParams:  makeSendParams(chat, text, msgId),
 			ReplyMarkup: keyboard,
 		}, &msg)
 	if err != nil {
 		return 0, err
 	}
-	return msg.Result.MessageId, nil
+	return msg.MessageId, nil
 }
 
-func (c *tgClient) CreateAnswerKeyboard(ctx context.Context, chat uint64, text string, keyboard AnswerKeyboard) (uint64, error) {
+func (c *tgClient) CreateAnswerKeyboard(ctx context.Context, chat Recipient, text Text, keyboard AnswerKeyboard) (uint64, error) {
 	return c.EditAnswerKeyboard(ctx, chat, text, 0, keyboard)
 }
 
-func (c *tgClient) EditInputKeyboard(ctx context.Context, chat uint64, text string, msgId uint64, keyboard InlineKeyboard) (uint64, error) {
-	var msg SendResponse
+func (c *tgClient) EditInputKeyboard(ctx context.Context, chat Recipient, text Text, msgId uint64, keyboard InlineKeyboard) (uint64, error) {
+	var msg Message
 	var cmd = SendCmd
 	if msgId != 0 {
 		cmd = EditCmd
 	}
-	err := c.Request(ctx,
-		http.MethodPost, cmd,
+	text, err := c.sendText(ctx, chat, text, msgId)
+	if err != nil {
+		return 0, err
+	}
+	err = c.send(ctx,
+		chat.ChatId, cmd,
 		SendInlineKeyboard{
-			SendParams: SendParams{
-				ChatId:    chat,
-				Text:      text,
-				MessageId: msgId,
-			},
+			SendParams:  makeSendParams(chat, text, msgId),
 			ReplyMarkup: keyboard,
 		}, &msg)
 	if err != nil {
 		return 0, err
 	}
-	return msg.Result.MessageId, nil
+	return msg.MessageId, nil
 }
 
-func (c *tgClient) CreateInputKeyboard(ctx context.Context, chat uint64, text string, keyboard InlineKeyboard) (uint64, error) {
+func (c *tgClient) CreateInputKeyboard(ctx context.Context, chat Recipient, text Text, keyboard InlineKeyboard) (uint64, error) {
 	return c.EditInputKeyboard(ctx, chat, text, 0, keyboard)
 }
 
-func (c *tgClient) DropKeyboard(ctx context.Context, chat uint64, text string) error {
-	return c.Request(ctx,
-		http.MethodPost, SendCmd,
+func (c *tgClient) DropKeyboard(ctx context.Context, chat Recipient, text Text) error {
+	text, err := c.sendText(ctx, chat, text, 0)
+	if err != nil {
+		return err
+	}
+	return c.send(ctx,
+		chat.ChatId, SendCmd,
 		SendDropKeyboard{
-			SendParams: SendParams{
-				ChatId: chat,
-				Text:   text,
-			},
+			SendParams:  makeSendParams(chat, text, 0),
 			ReplyMarkup: DropKeyboard{RemoveKeyboard: true},
 		}, nil)
 }
+
+func (c *tgClient) SendForceReply(ctx context.Context, chat Recipient, text Text, reply ForceReply) (uint64, error) {
+	var msg Message
+	text, err := c.sendText(ctx, chat, text, 0)
+	if err != nil {
+		return 0, err
+	}
+	err = c.send(ctx,
+		chat.ChatId, SendCmd,
+		SendForceReply{
+			SendParams:  makeSendParams(chat, text, 0),
+			ReplyMarkup: reply,
+		}, &msg)
+	if err != nil {
+		return 0, err
+	}
+	return msg.MessageId, nil
+}
+
+func (c *tgClient) SetWebhook(ctx context.Context, webhook SetWebhook) error {
+	if webhook.Certificate == "" {
+		return c.Call(ctx, WebhookCmd, webhook, nil)
+	}
+	return c.callForm(ctx,
+		WebhookCmd,
+		webhook, map[string]httputils.FormFile{
+			"certificate": {Name: "certificate.pem", Reader: strings.NewReader(webhook.Certificate)},
+		}, nil)
+}
+
+func (c *tgClient) SendPhoto(ctx context.Context, chat Recipient, photo InputFile, caption Text, markup ReplyMarkup) (uint64, error) {
+	params := makeSendMedia(chat, caption, markup)
+	params.Photo = photo.FileId
+	return c.sendMedia(ctx, chat, PhotoCmd, "photo", photo, params)
+}
+
+func (c *tgClient) SendDocument(ctx context.Context, chat Recipient, document InputFile, caption Text, markup ReplyMarkup) (uint64, error) {
+	params := makeSendMedia(chat, caption, markup)
+	params.Document = document.FileId
+	return c.sendMedia(ctx, chat, DocumentCmd, "document", document, params)
+}
+
+func (c *tgClient) SendAudio(ctx context.Context, chat Recipient, audio InputFile, caption Text, markup ReplyMarkup) (uint64, error) {
+	params := makeSendMedia(chat, caption, markup)
+	params.Audio = audio.FileId
+	return c.sendMedia(ctx, chat, AudioCmd, "audio", audio, params)
+}
+
+func (c *tgClient) SendVoice(ctx context.Context, chat Recipient, voice InputFile, caption Text, markup ReplyMarkup) (uint64, error) {
+	params := makeSendMedia(chat, caption, markup)
+	params.Voice = voice.FileId
+	return c.sendMedia(ctx, chat, VoiceCmd, "voice", voice, params)
+}
+
+// sendMedia uploads file as field of multipart form, or sends its id as is
+func (c *tgClient) sendMedia(ctx context.Context, chat Recipient, cmd, field string, file InputFile, params SendMedia) (uint64, error) {
+	var msg Message
+	var err error
+	if file.Reader == nil {
+		err = c.send(ctx, chat.ChatId, cmd, params, &msg)
+	} else {
+		err = c.sendForm(ctx, chat.ChatId, cmd, params, map[string]httputils.FormFile{
+			field: {Name: file.Name, ContentType: file.ContentType, Reader: file.Reader},
+		}, &msg)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return msg.MessageId, nil
+}
+
+func (c *tgClient) GetFile(ctx context.Context, fileId string) (File, error) {
+	var res File
+	if err := c.Call(ctx, GetFileCmd, GetFile{FileId: fileId}, &res); err != nil {
+		return File{}, err
+	}
+	return res, nil
+}
+
+// DownloadFile streams file contents, failing with FileTooBigError over size limit
+func (c *tgClient) DownloadFile(ctx context.Context, fileId string) (io.ReadCloser, error) {
+	file, err := c.GetFile(ctx, fileId)
+	if err != nil {
+		return nil, xerrors.Errorf("get file: %w", err)
+	}
+	if file.FileSize > c.maxDownloadSize {
+		return nil, xerrors.Errorf("%d bytes: %w", file.FileSize, FileTooBigError)
+	}
+	if file.FilePath == "" {
+		return nil, xerrors.Errorf("no file path for %s", fileId)
+	}
+	client := c.BaseClient
+	if c.Client.Timeout > 0 {
+		// client timeout covers reading body, so it must be enough for largest file
+		httpClient := *c.Client
+		httpClient.Timeout += time.Duration(c.maxDownloadSize) * time.Second / minDownloadRate
+		client.Client = &httpClient
+	}
+	body, err := client.Get(ctx, c.filePath+file.FilePath)
+	if err != nil {
+		return nil, xerrors.Errorf("download: %w", apiError(err))
+	}
+	return &limitedReader{ReadCloser: body, left: c.maxDownloadSize}, nil
+}
+
+func (c *tgClient) SetMyCommands(ctx context.Context, commands []BotCommand, scope *BotCommandScope, language string) error {
+	return c.Call(ctx,
+		SetCommandsCmd,
+		SetMyCommands{
+			Commands:     commands,
+			Scope:        scope,
+			LanguageCode: language,
+		}, nil)
+}
+
+func (c *tgClient) GetMyCommands(ctx context.Context, scope *BotCommandScope, language string) ([]BotCommand, error) {
+	var res []BotCommand
+	err := c.Call(ctx,
+		GetCommandsCmd,
+		MyCommands{Scope: scope, LanguageCode: language}, &res)
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (c *tgClient) DeleteMyCommands(ctx context.Context, scope *BotCommandScope, language string) error {
+	return c.Call(ctx,
+		DeleteCommandsCmd,
+		MyCommands{Scope: scope, LanguageCode: language}, nil)
+}
+
+func (c *tgClient) AnswerInlineQuery(ctx context.Context, answer AnswerInlineQuery) error {
+	if answer.Results == nil {
+		// empty list is required to show "no results"
+		answer.Results = []InlineQueryResult{}
+	}
+	return c.Call(ctx, InlineAnswerCmd, answer, nil)
+}
+
+func (c *tgClient) DeleteMessage(ctx context.Context, chat Recipient, msgId uint64) error {
+	_, err := DeleteMessage(ctx, c, DeleteMessageParams{ChatId: chat.ChatId, MessageId: msgId})
+	return err
+}
+
+func (c *tgClient) DeleteMessages(ctx context.Context, chat Recipient, msgIds []uint64) error {
+	for len(msgIds) > 0 {
+		batch := msgIds
+		if len(batch) > maxDeleteMessages {
+			batch = batch[:maxDeleteMessages]
+		}
+		msgIds = msgIds[len(batch):]
+		if _, err := DeleteMessages(ctx, c, DeleteMessagesParams{ChatId: chat.ChatId, MessageIds: batch}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *tgClient) EditReplyMarkup(ctx context.Context, chat Recipient, msgId uint64, keyboard *InlineKeyboard) error {
+	params := EditMessageReplyMarkupParams{ChatId: chat.ChatId, MessageId: msgId}
+	if keyboard != nil {
+		// nil one removes keyboard
+		params.ReplyMarkup = *keyboard
+	}
+	_, err := EditMessageReplyMarkup(ctx, c, params)
+	return err
+}
+
+func (c *tgClient) PinMessage(ctx context.Context, chat Recipient, msgId uint64, silent bool) error {
+	_, err := PinChatMessage(ctx, c, PinChatMessageParams{ChatId: chat.ChatId, MessageId: msgId, DisableNotification: silent})
+	return err
+}
+
+func (c *tgClient) UnpinMessage(ctx context.Context, chat Recipient, msgId uint64) error {
+	_, err := UnpinChatMessage(ctx, c, UnpinChatMessageParams{ChatId: chat.ChatId, MessageId: msgId})
+	return err
+}
+
+// CopyMessage is rate limited by Call
+func (c *tgClient) CopyMessage(ctx context.Context, to Recipient, from Recipient, msgId uint64) (uint64, error) {
+	res, err := CopyMessage(ctx, c, CopyMessageParams{
+		ChatId:          to.ChatId,
+		MessageThreadId: to.ThreadId,
+		FromChatId:      from.ChatId,
+		MessageId:       msgId,
+	})
+	return res.MessageId, err
+}
+
+// ForwardMessage is rate limited by Call
+func (c *tgClient) ForwardMessage(ctx context.Context, to Recipient, from Recipient, msgId uint64) (uint64, error) {
+	res, err := ForwardMessage(ctx, c, ForwardMessageParams{
+		ChatId:          to.ChatId,
+		MessageThreadId: to.ThreadId,
+		FromChatId:      from.ChatId,
+		MessageId:       msgId,
+	})
+	return res.MessageId, err
+}
+
+// SendChatAction is not rate limited, it's not worth waiting for
+func (c *tgClient) SendChatAction(ctx context.Context, chat Recipient, action string) error {
+	_, err := SendChatAction(ctx, c, SendChatActionParams{ChatId: chat.ChatId, MessageThreadId: chat.ThreadId, Action: action})
+	return err
+}
+
+func (c *tgClient) SendPoll(ctx context.Context, chat Recipient, poll SendPoll) (Message, error) {
+	var msg Message
+	poll.ChatId = chat.ChatId
+	poll.MessageThreadId = chat.ThreadId
+	if err := c.send(ctx, chat.ChatId, PollCmd, poll, &msg); err != nil {
+		return Message{}, err
+	}
+	return msg, nil
+}
+
+func (c *tgClient) StopPoll(ctx context.Context, chat Recipient, msgId uint64) (Poll, error) {
+	var res Poll
+	err := c.send(ctx,
+		chat.ChatId, StopPollCmd,
+		StopPoll{ChatId: chat.ChatId, MessageId: msgId}, &res)
+	if err != nil {
+		return Poll{}, err
+	}
+	return res, nil
+}
+
+// limitedReader fails when more than allowed is read
+type limitedReader struct {
+	io.ReadCloser
+	left int64
+}
+
+func (r *limitedReader) Read(p []byte) (int, error) {
+	if r.left < 0 {
+		return 0, FileTooBigError
+	}
+	n, err := r.ReadCloser.Read(p)
+	r.left -= int64(n)
+	if r.left < 0 {
+		return n + int(r.left), FileTooBigError
+	}
+	return n, err
+}
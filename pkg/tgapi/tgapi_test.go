@@ -0,0 +1,561 @@
+package tgapi
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/xerrors"
+
+	"github.com/baldisbk/tgbot/pkg/httputils"
+)
+
+func testClient(t *testing.T, handler http.HandlerFunc) *tgClient {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	return &tgClient{BaseClient: httputils.BaseClient{
+		Client: server.Client(),
+		Path:   server.URL + "/bottoken/",
+	}}
+}
+
+func TestGetMe(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		status   int
+		response string
+		info     BotInfo
+		err      error
+	}{
+		{
+			desc:     "ok",
+			status:   http.StatusOK,
+			response: `{"ok":true,"result":{"id":42,"is_bot":true,"first_name":"Bot","username":"OurBot","can_join_groups":true,"supports_inline_queries":true}}`,
+			info:     BotInfo{Id: 42, IsBot: true, FirstName: "Bot", Username: "OurBot", CanJoinGroups: true, SupportsInlineQueries: true},
+		},
+		{
+			desc:     "unauthorized",
+			status:   http.StatusUnauthorized,
+			response: `{"ok":false,"error_code":401,"description":"Unauthorized"}`,
+			err:      InvalidTokenError,
+		},
+		{
+			desc:     "malformed token",
+			status:   http.StatusNotFound,
+			response: `{"ok":false,"error_code":404,"description":"Not Found"}`,
+			err:      InvalidTokenError,
+		},
+		{
+			desc:     "not bot api",
+			status:   http.StatusOK,
+			response: `{}`,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal("/bottoken/"+TestCmd, r.URL.Path)
+				rw.WriteHeader(c.status)
+				rw.Write([]byte(c.response))
+			})
+			err := client.Test(context.Background())
+			if c.info.Id == 0 {
+				assert.Error(err)
+				if c.err != nil {
+					assert.True(xerrors.Is(err, c.err), "%v", err)
+				}
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(c.info, client.BotInfo())
+		})
+	}
+}
+
+func TestSendText(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		chat     Recipient
+		text     Text
+		expected string
+	}{
+		{
+			desc:     "plain",
+			chat:     ToChat(1),
+			text:     PlainText("a<b"),
+			expected: `{"chat_id":1,"text":"a<b"}`,
+		},
+		{
+			desc:     "html",
+			chat:     ToChat(1),
+			text:     HTMLText("<b>bold</b>"),
+			expected: `{"chat_id":1,"text":"<b>bold</b>","parse_mode":"HTML"}`,
+		},
+		{
+			desc:     "entities",
+			chat:     ToChat(1),
+			text:     Text{Text: "bold", Entities: []MessageEntity{{Type: "bold", Length: 4}}},
+			expected: `{"chat_id":1,"text":"bold","entities":[{"type":"bold","offset":0,"length":4}]}`,
+		},
+		{
+			desc:     "forum topic",
+			chat:     Recipient{ChatId: -1001, ThreadId: 7},
+			text:     PlainText("hi"),
+			expected: `{"chat_id":-1001,"message_thread_id":7,"text":"hi"}`,
+		},
+		{
+			desc:     "reply",
+			chat:     ToChat(-1001).Reply(5),
+			text:     PlainText("hi"),
+			expected: `{"chat_id":-1001,"text":"hi","reply_parameters":{"message_id":5,"allow_sending_without_reply":true}}`,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(err)
+				assert.JSONEq(c.expected, string(body))
+				rw.Write([]byte(`{"ok":true,"result":{"message_id":42}}`))
+			})
+			id, err := client.SendMessage(context.Background(), c.chat, c.text)
+			assert.NoError(err)
+			assert.Equal(uint64(42), id)
+		})
+	}
+}
+
+func TestSendMedia(t *testing.T) {
+	keyboard := InlineKeyboard{InlineKeyboard: [][]InlineKeyboardButton{{{Text: "OK", CallbackData: "ok"}}}}
+	testCases := []struct {
+		desc   string
+		file   InputFile
+		fields map[string]string
+		upload string
+	}{
+		{
+			desc: "file id",
+			file: InputFile{FileId: "file-id"},
+			fields: map[string]string{
+				"chat_id":  "1",
+				"document": "file-id",
+				"caption":  "caption",
+			},
+		},
+		{
+			desc: "upload",
+			file: InputFile{Name: "data.csv", ContentType: "text/csv", Reader: strings.NewReader("a,b\n1,2\n")},
+			fields: map[string]string{
+				"chat_id": "1",
+				"caption": "caption",
+			},
+			upload: "a,b\n1,2\n",
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal("/bottoken/"+DocumentCmd, r.URL.Path)
+				fields := map[string]string{}
+				var markup string
+				if c.upload == "" {
+					var body map[string]interface{}
+					assert.NoError(json.NewDecoder(r.Body).Decode(&body))
+					for name, value := range body {
+						if name == "reply_markup" {
+							b, _ := json.Marshal(value)
+							markup = string(b)
+							continue
+						}
+						b, _ := json.Marshal(value)
+						fields[name] = strings.Trim(string(b), `"`)
+					}
+				} else {
+					assert.NoError(r.ParseMultipartForm(1 << 20))
+					for name, values := range r.MultipartForm.Value {
+						if name == "reply_markup" {
+							markup = values[0]
+							continue
+						}
+						fields[name] = values[0]
+					}
+					file, header, err := r.FormFile("document")
+					assert.NoError(err)
+					assert.Equal("data.csv", header.Filename)
+					assert.Equal("text/csv", header.Header.Get("Content-Type"))
+					contents, err := io.ReadAll(file)
+					assert.NoError(err)
+					assert.Equal(c.upload, string(contents))
+				}
+				assert.Equal(c.fields, fields)
+				assert.JSONEq(`{"inline_keyboard":[[{"text":"OK","callback_data":"ok"}]]}`, markup)
+				rw.Write([]byte(`{"ok":true,"result":{"message_id":42}}`))
+			})
+			id, err := client.SendDocument(context.Background(), ToChat(1), c.file, PlainText("caption"), keyboard)
+			assert.NoError(err)
+			assert.Equal(uint64(42), id)
+		})
+	}
+}
+
+func TestDownloadFile(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		file     string
+		response string
+		err      error
+	}{
+		{
+			desc:     "ok",
+			file:     "contents",
+			response: `{"ok":true,"result":{"file_id":"id","file_size":8,"file_path":"documents/file_1.txt"}}`,
+		},
+		{
+			desc:     "too big",
+			file:     "contents",
+			response: `{"ok":true,"result":{"file_id":"id","file_size":100,"file_path":"documents/file_1.txt"}}`,
+			err:      FileTooBigError,
+		},
+		{
+			desc:     "too big unknown size",
+			file:     "long contents",
+			response: `{"ok":true,"result":{"file_id":"id","file_path":"documents/file_1.txt"}}`,
+			err:      FileTooBigError,
+		},
+		{
+			desc:     "not found",
+			response: `{"ok":false,"error_code":400,"description":"Bad Request: invalid file_id"}`,
+			err:      BadRequestError,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/bottoken/" + GetFileCmd:
+					var req GetFile
+					assert.NoError(json.NewDecoder(r.Body).Decode(&req))
+					assert.Equal("id", req.FileId)
+					if c.file == "" {
+						rw.WriteHeader(http.StatusBadRequest)
+					}
+					rw.Write([]byte(c.response))
+				case "/file/bottoken/documents/file_1.txt":
+					rw.Write([]byte(c.file))
+				default:
+					rw.WriteHeader(http.StatusNotFound)
+				}
+			})
+			client.filePath = strings.Replace(client.Path, "/bottoken/", "/file/bottoken/", 1)
+			client.maxDownloadSize = 10
+
+			var contents []byte
+			body, err := client.DownloadFile(context.Background(), "id")
+			if err == nil {
+				defer body.Close()
+				contents, err = io.ReadAll(body)
+			}
+			if c.err != nil {
+				assert.True(xerrors.Is(err, c.err), "%v", err)
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(c.file, string(contents))
+		})
+	}
+}
+
+func TestDownloadFileSlow(t *testing.T) {
+	assert := require.New(t)
+	client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/bottoken/" + GetFileCmd:
+			rw.Write([]byte(`{"ok":true,"result":{"file_id":"id","file_size":8,"file_path":"documents/file_1.txt"}}`))
+		default:
+			// body takes longer than client timeout
+			rw.Write([]byte("cont"))
+			rw.(http.Flusher).Flush()
+			time.Sleep(100 * time.Millisecond)
+			rw.Write([]byte("ents"))
+		}
+	})
+	client.filePath = strings.Replace(client.Path, "/bottoken/", "/file/bottoken/", 1)
+	client.Client.Timeout = 50 * time.Millisecond
+	client.maxDownloadSize = 1 << 20
+
+	body, err := client.DownloadFile(context.Background(), "id")
+	assert.NoError(err)
+	defer body.Close()
+	contents, err := io.ReadAll(body)
+	assert.NoError(err)
+	assert.Equal("contents", string(contents))
+}
+
+func TestCallbackAnswer(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		answer   *AnswerCallback
+		expected AnswerCallback
+	}{
+		{
+			desc:     "empty",
+			expected: AnswerCallback{CallbackQueryId: "q"},
+		},
+		{
+			desc:     "alert",
+			answer:   &AnswerCallback{Text: "Wrong", ShowAlert: true},
+			expected: AnswerCallback{CallbackQueryId: "q", Text: "Wrong", ShowAlert: true},
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			ctx := context.Background()
+			client := NewMock()
+			client.On("AnswerCallback", ctx, c.expected).Return(nil).Once()
+
+			query := &CallbackQuery{Id: "q"}
+			assert.NoError(query.PreProcess(ctx, client))
+			if c.answer != nil {
+				query.SetAnswer(*c.answer)
+			}
+			assert.NoError(query.PostProcess(ctx, client))
+			client.AssertExpectations(t)
+		})
+	}
+}
+
+func TestSendMarkup(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		send     func(TGClient) (uint64, error)
+		expected string
+	}{
+		{
+			desc: "answer keyboard",
+			send: func(c TGClient) (uint64, error) {
+				return c.CreateAnswerKeyboard(context.Background(), ToChat(1), PlainText("how many?"), AnswerKeyboard{
+					Keyboard: [][]AnswerKeyboardButton{
+						{{Text: "10"}, {Text: "100"}},
+						{{Text: "Share contact", RequestContact: true}, {Text: "Share location", RequestLocation: true}},
+					},
+					Resize:      true,
+					OneTime:     true,
+					Placeholder: "Enter a number",
+					Selective:   true,
+				})
+			},
+			expected: `{"chat_id":1,"text":"how many?","reply_markup":{
+				"keyboard":[[{"text":"10"},{"text":"100"}],
+					[{"text":"Share contact","request_contact":true},{"text":"Share location","request_location":true}]],
+				"resize_keyboard":true,"one_time_keyboard":true,
+				"input_field_placeholder":"Enter a number","selective":true}}`,
+		},
+		{
+			desc: "persistent keyboard",
+			send: func(c TGClient) (uint64, error) {
+				return c.CreateAnswerKeyboard(context.Background(), ToChat(1), PlainText("menu"), AnswerKeyboard{
+					Keyboard:     [][]AnswerKeyboardButton{{{Text: "Menu"}}},
+					IsPersistent: true,
+				})
+			},
+			expected: `{"chat_id":1,"text":"menu","reply_markup":{"keyboard":[[{"text":"Menu"}]],"is_persistent":true}}`,
+		},
+		{
+			desc: "force reply",
+			send: func(c TGClient) (uint64, error) {
+				return c.SendForceReply(context.Background(), ToChat(1), PlainText("name?"), ForceReply{Placeholder: "Name"})
+			},
+			expected: `{"chat_id":1,"text":"name?","reply_markup":{"force_reply":true,"input_field_placeholder":"Name"}}`,
+		},
+		{
+			desc: "selective force reply",
+			send: func(c TGClient) (uint64, error) {
+				return c.SendForceReply(context.Background(), ToChat(-1001).Reply(5), PlainText("name?"),
+					ForceReply{Placeholder: "Name", Selective: true})
+			},
+			expected: `{"chat_id":-1001,"text":"name?","reply_parameters":{"message_id":5,"allow_sending_without_reply":true},
+				"reply_markup":{"force_reply":true,"input_field_placeholder":"Name","selective":true}}`,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal("/bottoken/"+SendCmd, r.URL.Path)
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(err)
+				assert.JSONEq(c.expected, string(body))
+				rw.Write([]byte(`{"ok":true,"result":{"message_id":42}}`))
+			})
+			id, err := c.send(client)
+			assert.NoError(err)
+			assert.Equal(uint64(42), id)
+		})
+	}
+}
+
+func TestCall(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		status   int
+		response string
+		count    int
+		err      error
+	}{
+		{
+			desc:     "ok",
+			status:   http.StatusOK,
+			response: `{"ok":true,"result":42}`,
+			count:    42,
+		},
+		{
+			desc:     "api error",
+			status:   http.StatusBadRequest,
+			response: `{"ok":false,"error_code":400,"description":"Bad Request: chat not found"}`,
+			err:      BadRequestError,
+		},
+		{
+			desc:     "not ok",
+			status:   http.StatusOK,
+			response: `{"ok":false}`,
+			err:      xerrors.New("any"),
+		},
+		{
+			desc:     "bad result",
+			status:   http.StatusOK,
+			response: `{"ok":true,"result":"many"}`,
+			err:      xerrors.New("any"),
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal("/bottoken/"+GetChatMemberCountCmd, r.URL.Path)
+				body, err := io.ReadAll(r.Body)
+				assert.NoError(err)
+				assert.JSONEq(`{"chat_id":-100}`, string(body))
+				rw.WriteHeader(c.status)
+				rw.Write([]byte(c.response))
+			})
+			count, err := GetChatMemberCount(context.Background(), client, GetChatMemberCountParams{ChatId: -100})
+			if c.err != nil {
+				assert.Error(err)
+				if c.err == BadRequestError {
+					assert.True(xerrors.Is(err, c.err), "%v", err)
+				}
+				return
+			}
+			assert.NoError(err)
+			assert.Equal(c.count, count)
+		})
+	}
+}
+
+func TestNewClientProxy(t *testing.T) {
+	assert := require.New(t)
+	api := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		assert.Equal("/bottoken/"+TestCmd, r.URL.Path)
+		rw.Write([]byte(`{"ok":true,"result":{"id":42,"is_bot":true,"first_name":"Bot"}}`))
+	}))
+	defer api.Close()
+	var proxied int
+	proxy := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, r *http.Request) {
+		user, password, ok := parseProxyAuth(r.Header.Get("Proxy-Authorization"))
+		if !ok || user != "user" || password != "secret" {
+			rw.WriteHeader(http.StatusProxyAuthRequired)
+			return
+		}
+		proxied++
+		r.RequestURI = ""
+		r.Header.Del("Proxy-Authorization")
+		rsp, err := http.DefaultTransport.RoundTrip(r)
+		if err != nil {
+			rw.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		defer rsp.Body.Close()
+		rw.WriteHeader(rsp.StatusCode)
+		io.Copy(rw, rsp.Body)
+	}))
+	defer proxy.Close()
+
+	cfg := Config{
+		Address: api.URL,
+		Token:   "token",
+		Transport: httputils.TransportConfig{Proxy: httputils.ProxyConfig{
+			URL: proxy.URL, Username: "user", Password: "wrong",
+		}},
+	}
+	_, err := NewClient(context.Background(), cfg)
+	assert.Error(err)
+	assert.NotContains(fmt.Sprintf("%#v", cfg), "wrong")
+
+	cfg.Transport.Proxy.Password = "secret"
+	client, err := NewClient(context.Background(), cfg)
+	assert.NoError(err)
+	assert.Equal(uint64(42), client.BotInfo().Id)
+	assert.Equal(1, proxied)
+}
+
+func parseProxyAuth(header string) (string, string, bool) {
+	r := http.Request{Header: http.Header{"Authorization": {header}}}
+	return r.BasicAuth()
+}
+
+func TestSetWebhook(t *testing.T) {
+	const cert = "-----BEGIN CERTIFICATE-----\nMIIB\n-----END CERTIFICATE-----\n"
+	testCases := []struct {
+		desc    string
+		webhook SetWebhook
+		form    bool
+	}{
+		{
+			desc:    "plain",
+			webhook: SetWebhook{URL: "https://bot.example.com/hook", SecretToken: "secret"},
+		},
+		{
+			desc:    "self-signed",
+			webhook: SetWebhook{URL: "https://bot.example.com/hook", SecretToken: "secret", Certificate: cert},
+			form:    true,
+		},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			client := testClient(t, func(rw http.ResponseWriter, r *http.Request) {
+				assert.Equal("/bottoken/"+WebhookCmd, r.URL.Path)
+				if !c.form {
+					body, err := io.ReadAll(r.Body)
+					assert.NoError(err)
+					assert.JSONEq(`{"url":"https://bot.example.com/hook","secret_token":"secret"}`, string(body))
+				} else {
+					assert.NoError(r.ParseMultipartForm(1 << 20))
+					assert.Equal("https://bot.example.com/hook", r.FormValue("url"))
+					assert.Equal("secret", r.FormValue("secret_token"))
+					file, header, err := r.FormFile("certificate")
+					assert.NoError(err)
+					defer file.Close()
+					assert.Equal("certificate.pem", header.Filename)
+					contents, err := io.ReadAll(file)
+					assert.NoError(err)
+					assert.Equal(cert, string(contents))
+				}
+				rw.Write([]byte(`{"ok":true,"result":true}`))
+			})
+			assert.NoError(client.SetWebhook(context.Background(), c.webhook))
+		})
+	}
+}
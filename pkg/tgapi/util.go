@@ -6,7 +6,8 @@ import (
 	"encoding/json"
 )
 
-func hash(u Update) {
+// Hash sets UUIDs of update contents for logging
+func Hash(u Update) {
 	if u.Message != nil {
 		b, _ := json.Marshal(u.Message)
 		h := md5.Sum(b)
@@ -17,4 +18,39 @@ func hash(u Update) {
 		h := md5.Sum(b)
 		u.CallbackQuery.UUID = hex.EncodeToString(h[:])
 	}
+	if u.InlineQuery != nil {
+		b, _ := json.Marshal(u.InlineQuery)
+		h := md5.Sum(b)
+		u.InlineQuery.UUID = hex.EncodeToString(h[:])
+	}
+	if u.ChosenInlineResult != nil {
+		b, _ := json.Marshal(u.ChosenInlineResult)
+		h := md5.Sum(b)
+		u.ChosenInlineResult.UUID = hex.EncodeToString(h[:])
+	}
+	if u.EditedMessage != nil {
+		b, _ := json.Marshal(u.EditedMessage)
+		h := md5.Sum(b)
+		u.EditedMessage.UUID = hex.EncodeToString(h[:])
+	}
+	if u.MyChatMember != nil {
+		b, _ := json.Marshal(u.MyChatMember)
+		h := md5.Sum(b)
+		u.MyChatMember.UUID = hex.EncodeToString(h[:])
+	}
+	if u.ChatMember != nil {
+		b, _ := json.Marshal(u.ChatMember)
+		h := md5.Sum(b)
+		u.ChatMember.UUID = hex.EncodeToString(h[:])
+	}
+	if u.Poll != nil {
+		b, _ := json.Marshal(u.Poll)
+		h := md5.Sum(b)
+		u.Poll.UUID = hex.EncodeToString(h[:])
+	}
+	if u.PollAnswer != nil {
+		b, _ := json.Marshal(u.PollAnswer)
+		h := md5.Sum(b)
+		u.PollAnswer.UUID = hex.EncodeToString(h[:])
+	}
 }
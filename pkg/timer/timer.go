@@ -24,24 +24,38 @@ type timerKey struct {
 	Name string
 }
 
+type receiverKey struct {
+	ChatId int64
+	UserId uint64
+}
+
 type TimerEvent struct {
 	UUID     string
 	Type     string
 	Name     string
 	Receiver tgapi.User
+	Chat     tgapi.Chat
+	ThreadId int64 // forum topic, 0 for general
 	Time     time.Time
 }
 
 func (t *TimerEvent) key() timerKey { return timerKey{Type: t.Type, Name: t.Name} }
+func (t *TimerEvent) receiver() receiverKey {
+	return receiverKey{ChatId: t.Chat.Id, UserId: t.Receiver.Id}
+}
 
 func (t *TimerEvent) User() tgapi.User                                             { return t.Receiver }
+func (t *TimerEvent) Origin() tgapi.Chat                                           { return t.Chat }
 func (t *TimerEvent) Message() interface{}                                         { return t }
 func (t *TimerEvent) PreProcess(ctx context.Context, client tgapi.TGClient) error  { return nil }
 func (t *TimerEvent) PostProcess(ctx context.Context, client tgapi.TGClient) error { return nil }
+func (t *TimerEvent) Recipient() tgapi.Recipient {
+	return tgapi.Recipient{ChatId: t.Chat.Id, ThreadId: t.ThreadId}
+}
 
 type Timer struct {
 	mx     sync.Mutex
-	events map[tgapi.User]map[timerKey]time.Time
+	events map[receiverKey]map[timerKey]*TimerEvent // current one of each key, queued or running
 	queue  []*TimerEvent
 
 	clock    clockwork.Clock
@@ -67,7 +81,7 @@ func (t *Timer) advance(d time.Duration) {
 func newTimer(ctx context.Context, eng engine.Engine, clock clockwork.Clock, period time.Duration) *Timer {
 	ticker := clock.NewTicker(period)
 	res := &Timer{
-		events:  map[tgapi.User]map[timerKey]time.Time{},
+		events:  map[receiverKey]map[timerKey]*TimerEvent{},
 		clock:   clock,
 		stopper: make(chan struct{}),
 	}
@@ -91,15 +105,27 @@ func newTimer(ctx context.Context, eng engine.Engine, clock clockwork.Clock, per
 						res.mx.Lock()
 						defer res.mx.Unlock()
 						defer wg.Done()
+						if res.events[event.receiver()][event.key()] != event {
+							// cancelled or set again while running
+							return
+						}
 						switch {
 						case xerrors.Is(err, engine.BadStateError),
 							xerrors.Is(err, engine.RetriableError):
-							// retry it next time
+							// retry it next time or when told by API
+							var apiErr *tgapi.Error
+							if xerrors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+								event.Time = now.Add(apiErr.RetryAfter)
+							}
 							res.queue = append(res.queue, event)
+							res.sortQueue()
+						case xerrors.Is(err, engine.FatalError):
+							// receiver is unreachable, drop it
+							delete(res.events[event.receiver()], event.key())
 						case err != nil:
 							// TODO: process it somehow
 						default:
-							delete(res.events[event.Receiver], event.key())
+							delete(res.events[event.receiver()], event.key())
 						}
 					}(event)
 				}
@@ -119,31 +145,88 @@ func newTimer(ctx context.Context, eng engine.Engine, clock clockwork.Clock, per
 	return res
 }
 
-func (t *Timer) SetAlarm(user tgapi.User, name string, typ string, at time.Time) {
+// SetAlarm sends event to user in chat (and forum topic, if any) at given time,
+// replacing one with same type and name
+func (t *Timer) SetAlarm(chat tgapi.Chat, thread int64, user tgapi.User, name string, typ string, at time.Time) {
 	t.mx.Lock()
 	defer t.mx.Unlock()
 
-	if _, ok := t.events[user]; !ok {
-		t.events[user] = map[timerKey]time.Time{}
+	receiver := receiverKey{ChatId: chat.Id, UserId: user.Id}
+	if _, ok := t.events[receiver]; !ok {
+		t.events[receiver] = map[timerKey]*TimerEvent{}
 	}
 	key := timerKey{typ, name}
-	if old, ok := t.events[user][key]; ok {
-		if old.Equal(at) {
+	if event, ok := t.events[receiver][key]; ok && t.queued(event) {
+		// topic may change with same time
+		event.ThreadId = thread
+		if event.Time.Equal(at) {
 			return
 		}
 		// replace
-		t.events[user][key] = at
-		i := sort.Search(len(t.queue), func(i int) bool { return !t.queue[i].Time.Before(old) })
-		t.queue[i].Time = at
-	} else {
-		t.events[user][key] = at
-		t.queue = append(t.queue, &TimerEvent{
-			UUID:     uuid.NewString(),
-			Name:     name,
-			Type:     typ,
-			Receiver: user,
-			Time:     at,
-		})
+		event.Time = at
+		t.sortQueue()
+		return
+	}
+	// new one, or one replacing running event, which is dropped when done
+	event := &TimerEvent{
+		UUID:     uuid.NewString(),
+		Name:     name,
+		Type:     typ,
+		Receiver: user,
+		Chat:     chat,
+		ThreadId: thread,
+		Time:     at,
+	}
+	t.events[receiver][key] = event
+	t.queue = append(t.queue, event)
+	t.sortQueue()
+}
+
+// Cancel drops all events of user in chat
+func (t *Timer) Cancel(chat tgapi.Chat, user tgapi.User) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	receiver := receiverKey{ChatId: chat.Id, UserId: user.Id}
+	t.cancel(func(r receiverKey) bool { return r == receiver })
+}
+
+// CancelChat drops all events of all users in chat
+func (t *Timer) CancelChat(chat tgapi.Chat) {
+	t.mx.Lock()
+	defer t.mx.Unlock()
+
+	t.cancel(func(r receiverKey) bool { return r.ChatId == chat.Id })
+}
+
+// must be called under lock
+func (t *Timer) cancel(match func(receiverKey) bool) {
+	for receiver := range t.events {
+		if match(receiver) {
+			delete(t.events, receiver)
+		}
+	}
+	queue := t.queue[:0]
+	for _, event := range t.queue {
+		if !match(event.receiver()) {
+			queue = append(queue, event)
+		}
 	}
-	sort.Slice(t.queue, func(i, j int) bool { return t.queue[i].Time.Before(t.queue[i].Time) })
+	t.queue = queue
+}
+
+// queued is true if event is waiting in queue, false if it is being processed;
+// must be called under lock
+func (t *Timer) queued(event *TimerEvent) bool {
+	for _, e := range t.queue {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// must be called under lock
+func (t *Timer) sortQueue() {
+	sort.SliceStable(t.queue, func(i, j int) bool { return t.queue[i].Time.Before(t.queue[j].Time) })
 }
@@ -53,6 +53,8 @@ func TestTimer(t *testing.T) {
 
 			user1 := tgapi.User{Id: 1}
 			user2 := tgapi.User{Id: 2}
+			chat1 := tgapi.Chat{Id: 1, Type: tgapi.PrivateChat}
+			chat2 := tgapi.Chat{Id: -100, Type: tgapi.GroupChat}
 
 			var received1, received2 bool
 			engine := engine.NewEngineMock()
@@ -63,6 +65,7 @@ func TestTimer(t *testing.T) {
 					return e.Type == "1" &&
 						e.Name == "1" &&
 						e.Receiver == user1 &&
+						e.Chat == chat1 &&
 						e.Time == alarm1
 				})).
 				Return(nil).
@@ -74,6 +77,8 @@ func TestTimer(t *testing.T) {
 					return e.Type == "2" &&
 						e.Name == "2" &&
 						e.Receiver == user2 &&
+						e.Chat == chat2 &&
+						e.Recipient() == tgapi.Recipient{ChatId: -100, ThreadId: 7} &&
 						e.Time == alarm2
 				})).
 				Return(nil).
@@ -83,10 +88,10 @@ func TestTimer(t *testing.T) {
 			defer timer.Shutdown()
 
 			if c.alarm1 != 0 {
-				timer.SetAlarm(user1, "1", "1", alarm1)
+				timer.SetAlarm(chat1, 0, user1, "1", "1", alarm1)
 			}
 			if c.alarm2 != 0 {
-				timer.SetAlarm(user2, "2", "2", alarm2)
+				timer.SetAlarm(chat2, 7, user2, "2", "2", alarm2)
 			}
 
 			for clock.Now().Before(duration) {
@@ -110,6 +115,7 @@ func TestTimerReset(t *testing.T) {
 	duration := clock.Now().Add(2 * time.Second)
 
 	user := tgapi.User{Id: 1}
+	chat := tgapi.Chat{Id: 1, Type: tgapi.PrivateChat}
 
 	var received bool
 	engine := engine.NewEngineMock()
@@ -122,8 +128,8 @@ func TestTimerReset(t *testing.T) {
 		Run(func(args mock.Arguments) { received = true })
 
 	timer := newTimer(ctx, engine, clock, time.Second)
-	timer.SetAlarm(user, "1", "1", alarm1)
-	timer.SetAlarm(user, "1", "1", alarm2)
+	timer.SetAlarm(chat, 0, user, "1", "1", alarm1)
+	timer.SetAlarm(chat, 0, user, "1", "1", alarm2)
 
 	for clock.Now().Before(duration) {
 		assert.False(received)
@@ -131,3 +137,196 @@ func TestTimerReset(t *testing.T) {
 	}
 	assert.True(received)
 }
+
+func TestTimerCancel(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	clock := clockwork.NewFakeClock()
+	alarm := clock.Now().Add(1 * time.Second)
+	duration := clock.Now().Add(2 * time.Second)
+
+	user1 := tgapi.User{Id: 1}
+	user2 := tgapi.User{Id: 2}
+	chat1 := tgapi.Chat{Id: 1, Type: tgapi.PrivateChat}
+	chat2 := tgapi.Chat{Id: 2, Type: tgapi.PrivateChat}
+
+	var received1, received2 bool
+	engine := engine.NewEngineMock()
+	engine.On(
+		"Receive",
+		mock.Anything,
+		mock.MatchedBy(func(e *TimerEvent) bool { return e.Receiver == user1 }),
+	).
+		Return(nil).
+		Run(func(args mock.Arguments) { received1 = true })
+	engine.On(
+		"Receive",
+		mock.Anything,
+		mock.MatchedBy(func(e *TimerEvent) bool { return e.Receiver == user2 }),
+	).
+		Return(nil).
+		Run(func(args mock.Arguments) { received2 = true })
+
+	timer := newTimer(ctx, engine, clock, time.Second)
+	defer timer.Shutdown()
+	timer.SetAlarm(chat1, 0, user1, "1", "1", alarm)
+	timer.SetAlarm(chat1, 0, user1, "2", "1", alarm)
+	timer.SetAlarm(chat2, 0, user2, "1", "1", alarm)
+	timer.Cancel(chat1, user1)
+
+	for clock.Now().Before(duration) {
+		timer.advance(time.Second)
+	}
+	assert.False(received1)
+	assert.True(received2)
+
+	// can be set again
+	timer.SetAlarm(chat1, 0, user1, "1", "1", clock.Now().Add(time.Second))
+	timer.advance(time.Second)
+	assert.True(received1)
+}
+
+func TestTimerCancelChat(t *testing.T) {
+	assert := require.New(t)
+	ctx := context.Background()
+
+	clock := clockwork.NewFakeClock()
+	alarm := clock.Now().Add(1 * time.Second)
+	duration := clock.Now().Add(2 * time.Second)
+
+	user1 := tgapi.User{Id: 1}
+	user2 := tgapi.User{Id: 2}
+	group := tgapi.Chat{Id: -100, Type: tgapi.GroupChat}
+	private := tgapi.Chat{Id: 1, Type: tgapi.PrivateChat}
+
+	var inGroup, inPrivate bool
+	engine := engine.NewEngineMock()
+	engine.On(
+		"Receive",
+		mock.Anything,
+		mock.MatchedBy(func(e *TimerEvent) bool { return e.Chat == group }),
+	).
+		Return(nil).
+		Run(func(args mock.Arguments) { inGroup = true })
+	engine.On(
+		"Receive",
+		mock.Anything,
+		mock.MatchedBy(func(e *TimerEvent) bool { return e.Chat == private }),
+	).
+		Return(nil).
+		Run(func(args mock.Arguments) { inPrivate = true })
+
+	timer := newTimer(ctx, engine, clock, time.Second)
+	defer timer.Shutdown()
+	timer.SetAlarm(group, 0, user1, "1", "1", alarm)
+	timer.SetAlarm(group, 0, user2, "1", "1", alarm)
+	timer.SetAlarm(private, 0, user1, "1", "1", alarm)
+	timer.CancelChat(group)
+
+	for clock.Now().Before(duration) {
+		timer.advance(time.Second)
+	}
+	assert.False(inGroup)
+	assert.True(inPrivate)
+}
+
+// retriableError is API error classified as retriable by engine
+type retriableError struct{ err *tgapi.Error }
+
+func (e retriableError) Error() string        { return e.err.Error() }
+func (e retriableError) Unwrap() error        { return e.err }
+func (e retriableError) Is(target error) bool { return target == engine.RetriableError }
+
+func TestTimerCancelRunning(t *testing.T) {
+	testCases := []struct {
+		desc       string
+		retryAfter time.Duration
+	}{
+		{desc: "retry after", retryAfter: time.Second},
+		{desc: "retry next time"},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			ctx := context.Background()
+
+			clock := clockwork.NewFakeClock()
+			user := tgapi.User{Id: 1}
+			chat := tgapi.Chat{Id: 1, Type: tgapi.PrivateChat}
+
+			var timer *Timer
+			var received int
+			eng := engine.NewEngineMock()
+			eng.On("Receive", mock.Anything, mock.Anything).
+				Return(retriableError{&tgapi.Error{Code: 429, RetryAfter: c.retryAfter}}).
+				Run(func(args mock.Arguments) {
+					received++
+					// e.g. bot was blocked meanwhile
+					timer.Cancel(chat, user)
+				})
+
+			timer = newTimer(ctx, eng, clock, time.Second)
+			defer timer.Shutdown()
+			timer.SetAlarm(chat, 0, user, "1", "1", clock.Now().Add(time.Second))
+			for i := 0; i < 3; i++ {
+				timer.advance(time.Second)
+			}
+			assert.Equal(1, received)
+			timer.mx.Lock()
+			defer timer.mx.Unlock()
+			assert.Empty(timer.queue)
+		})
+	}
+}
+
+func TestTimerSetRunning(t *testing.T) {
+	testCases := []struct {
+		desc string
+		err  error
+	}{
+		{desc: "success"},
+		{desc: "retry", err: retriableError{&tgapi.Error{Code: 500}}},
+	}
+	for _, c := range testCases {
+		t.Run(c.desc, func(t *testing.T) {
+			assert := require.New(t)
+			ctx := context.Background()
+
+			clock := clockwork.NewFakeClock()
+			user := tgapi.User{Id: 1}
+			chat := tgapi.Chat{Id: 1, Type: tgapi.PrivateChat}
+			rearm := clock.Now().Add(3 * time.Second)
+
+			var timer *Timer
+			var received []time.Time
+			eng := engine.NewEngineMock()
+			eng.On("Receive", mock.Anything, mock.Anything).
+				Return(c.err).Once().
+				Run(func(args mock.Arguments) {
+					received = append(received, args[1].(*TimerEvent).Time)
+					// e.g. reminder postponed by its own handler
+					timer.SetAlarm(chat, 0, user, "1", "1", rearm)
+				})
+			eng.On("Receive", mock.Anything, mock.Anything).
+				Return(nil).
+				Run(func(args mock.Arguments) {
+					received = append(received, args[1].(*TimerEvent).Time)
+				})
+
+			timer = newTimer(ctx, eng, clock, time.Second)
+			defer timer.Shutdown()
+			first := clock.Now().Add(time.Second)
+			timer.SetAlarm(chat, 0, user, "1", "1", first)
+			for i := 0; i < 5; i++ {
+				timer.advance(time.Second)
+			}
+			// fired once at each time, not lost nor retried
+			assert.Equal([]time.Time{first, rearm}, received)
+			timer.mx.Lock()
+			defer timer.mx.Unlock()
+			assert.Empty(timer.queue)
+			assert.Empty(timer.events[receiverKey{ChatId: 1, UserId: 1}])
+		})
+	}
+}
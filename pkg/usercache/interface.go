@@ -12,11 +12,48 @@ type User interface {
 }
 
 type UserCache interface {
-	Get(context.Context, tgapi.User) (User, error)
-	Put(context.Context, tgapi.User, User) error
+	Get(context.Context, tgapi.Chat, tgapi.User) (User, error)
+	Put(context.Context, tgapi.Chat, tgapi.User, User) error
 	Close()
 }
 
 type UserFactory interface {
-	MakeUser(context.Context, tgapi.User) User
+	MakeUser(context.Context, tgapi.Chat, tgapi.User) User
+}
+
+// KeyPolicy decides which state handles signal from user in chat
+type KeyPolicy string
+
+const (
+	UserPolicy     KeyPolicy = "user"      // one state per user in all chats, default
+	ChatUserPolicy KeyPolicy = "chat_user" // state per user in each chat
+	ChatPolicy     KeyPolicy = "chat"      // one state per chat shared by its members
+)
+
+// Key identifies state, zero fields are not used by policy
+type Key struct {
+	ChatId int64
+	UserId uint64
+}
+
+// Key of state, private chats are keyed by user with any policy
+func (p KeyPolicy) Key(chat tgapi.Chat, user tgapi.User) Key {
+	if chat.Id == 0 || chat.Id == int64(user.Id) {
+		return Key{UserId: user.Id}
+	}
+	switch p {
+	case ChatUserPolicy:
+		return Key{ChatId: chat.Id, UserId: user.Id}
+	case ChatPolicy:
+		return Key{ChatId: chat.Id}
+	}
+	return Key{UserId: user.Id}
+}
+
+func (p KeyPolicy) Valid() bool {
+	switch p {
+	case "", UserPolicy, ChatUserPolicy, ChatPolicy:
+		return true
+	}
+	return false
 }
@@ -0,0 +1,35 @@
+package usercache
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
+
+func TestKeyPolicy(t *testing.T) {
+	user := tgapi.User{Id: 1}
+	private := tgapi.Chat{Id: 1, Type: tgapi.PrivateChat}
+	group := tgapi.Chat{Id: -100, Type: tgapi.GroupChat}
+
+	testCases := []struct {
+		desc   string
+		policy KeyPolicy
+		chat   tgapi.Chat
+		key    Key
+	}{
+		{desc: "default", chat: group, key: Key{UserId: 1}},
+		{desc: "user", policy: UserPolicy, chat: group, key: Key{UserId: 1}},
+		{desc: "chat user", policy: ChatUserPolicy, chat: group, key: Key{ChatId: -100, UserId: 1}},
+		{desc: "chat", policy: ChatPolicy, chat: group, key: Key{ChatId: -100}},
+		{desc: "chat user private", policy: ChatUserPolicy, chat: private, key: Key{UserId: 1}},
+		{desc: "chat private", policy: ChatPolicy, chat: private, key: Key{UserId: 1}},
+		{desc: "no chat", policy: ChatPolicy, key: Key{UserId: 1}},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			require.Equal(t, tC.key, tC.policy.Key(tC.chat, user))
+		})
+	}
+}
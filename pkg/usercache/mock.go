@@ -38,13 +38,13 @@ func NewCacheMock() *cacheMock {
 	return &cacheMock{}
 }
 
-func (u *cacheMock) Get(ctx context.Context, user tgapi.User) (User, error) {
-	args := u.Called(ctx, user)
+func (u *cacheMock) Get(ctx context.Context, chat tgapi.Chat, user tgapi.User) (User, error) {
+	args := u.Called(ctx, chat, user)
 	return args[0].(User), args.Error(1)
 }
 
-func (u *cacheMock) Put(ctx context.Context, tgUser tgapi.User, user User) error {
-	args := u.Called(ctx, tgUser, user)
+func (u *cacheMock) Put(ctx context.Context, chat tgapi.Chat, tgUser tgapi.User, user User) error {
+	args := u.Called(ctx, chat, tgUser, user)
 	return args.Error(0)
 }
 
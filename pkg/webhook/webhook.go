@@ -0,0 +1,187 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+
+	"golang.org/x/xerrors"
+
+	"github.com/baldisbk/tgbot/pkg/engine"
+	"github.com/baldisbk/tgbot/pkg/httputils"
+	"github.com/baldisbk/tgbot/pkg/logging"
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
+
+type Webhook struct {
+	Client tgapi.TGClient
+	Engine engine.Engine
+
+	config   Config
+	listener net.Listener
+	server   *http.Server
+}
+
+type Config struct {
+	// public URL registered with setWebhook
+	URL string `yaml:"url" env:"TGBOT_WEBHOOK_URL"`
+	// local address to listen at
+	Address string `yaml:"address" env:"TGBOT_WEBHOOK_ADDRESS"`
+	// local path to serve, path of URL by default
+	Path               string `yaml:"path"`
+	DropPendingUpdates bool   `yaml:"drop_pending_updates"`
+	// X-Telegram-Bot-Api-Secret-Token value, not checked if empty
+	SecretToken string `yaml:"-" env:"TGBOT_WEBHOOK_SECRET"`
+	// TLS is terminated here if both are set
+	CertFile string `yaml:"cert_file"`
+	KeyFile  string `yaml:"key_file"`
+	// upload CertFile to telegram
+	SelfSigned bool `yaml:"self_signed"`
+}
+
+// GoString hides secret token when config is logged with %#v
+func (c Config) GoString() string {
+	type config Config
+	cfg := config(c)
+	cfg.SecretToken = httputils.NewRedactor(httputils.RedactConfig{}, c.SecretToken).String(c.SecretToken)
+	return fmt.Sprintf("%#v", cfg)
+}
+
+const secretTokenHeader = "X-Telegram-Bot-Api-Secret-Token"
+
+const (
+	// updates are way smaller, anything bigger is not from telegram
+	maxUpdateSize     = 1 << 20
+	readHeaderTimeout = 10 * time.Second
+)
+
+func NewWebhook(ctx context.Context, cfg Config, client tgapi.TGClient, engine engine.Engine) (*Webhook, error) {
+	listener, err := net.Listen("tcp", cfg.Address)
+	if err != nil {
+		return nil, xerrors.Errorf("listen: %w", err)
+	}
+	hook, err := newWebhook(ctx, cfg, listener, client, engine)
+	if err != nil {
+		listener.Close()
+		return nil, xerrors.Errorf("start: %w", err)
+	}
+	return hook, nil
+}
+
+func newWebhook(ctx context.Context, cfg Config, listener net.Listener,
+	client tgapi.TGClient, engine engine.Engine) (*Webhook, error) {
+	if (cfg.CertFile == "") != (cfg.KeyFile == "") {
+		return nil, xerrors.Errorf("both cert_file and key_file must be set for TLS")
+	}
+	if cfg.SelfSigned && cfg.CertFile == "" {
+		return nil, xerrors.Errorf("self_signed requires cert_file and key_file")
+	}
+	path := cfg.Path
+	if path == "" {
+		u, err := url.Parse(cfg.URL)
+		if err != nil {
+			return nil, xerrors.Errorf("parse url: %w", err)
+		}
+		path = u.Path
+	}
+	if path == "" {
+		path = "/"
+	}
+	hook := &Webhook{
+		Client:   client,
+		Engine:   engine,
+		config:   cfg,
+		listener: listener,
+	}
+	mx := http.NewServeMux()
+	mx.HandleFunc(path, hook.receive)
+	hook.server = &http.Server{
+		Handler:           mx,
+		BaseContext:       func(net.Listener) context.Context { return ctx },
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+	if cfg.CertFile != "" {
+		// fail here, not in serving goroutine after webhook is set
+		cert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, xerrors.Errorf("load key pair: %w", err)
+		}
+		hook.server.TLSConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	params := tgapi.SetWebhook{
+		URL:                cfg.URL,
+		DropPendingUpdates: cfg.DropPendingUpdates,
+		SecretToken:        cfg.SecretToken,
+	}
+	if cfg.SelfSigned {
+		cert, err := os.ReadFile(cfg.CertFile)
+		if err != nil {
+			return nil, xerrors.Errorf("read certificate: %w", err)
+		}
+		params.Certificate = string(cert)
+	}
+	go func() {
+		var err error
+		if hook.server.TLSConfig != nil {
+			err = hook.server.ServeTLS(listener, "", "")
+		} else {
+			err = hook.server.Serve(listener)
+		}
+		if err != nil && err != http.ErrServerClosed {
+			logging.S(ctx).Errorf("Serve error: %#v", err)
+		}
+	}()
+	if err := client.SetWebhook(ctx, params); err != nil {
+		hook.Shutdown()
+		return nil, xerrors.Errorf("set webhook: %w", err)
+	}
+	return hook, nil
+}
+
+func (w *Webhook) Shutdown() { w.server.Shutdown(context.Background()) }
+
+func (w *Webhook) receive(rw http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	if r.Method != http.MethodPost {
+		rw.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if w.config.SecretToken != "" {
+		token := r.Header.Get(secretTokenHeader)
+		if subtle.ConstantTimeCompare([]byte(token), []byte(w.config.SecretToken)) != 1 {
+			logging.S(ctx).Warnf("Bad secret token from %s", r.RemoteAddr)
+			rw.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+	}
+	cts, err := io.ReadAll(http.MaxBytesReader(rw, r.Body, maxUpdateSize))
+	if err != nil {
+		logging.S(ctx).Errorf("Read update: %#v", err)
+		var tooLarge *http.MaxBytesError
+		if xerrors.As(err, &tooLarge) {
+			rw.WriteHeader(http.StatusRequestEntityTooLarge)
+			return
+		}
+		rw.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	var upd tgapi.Update
+	if err := json.Unmarshal(cts, &upd); err != nil {
+		logging.S(ctx).Errorf("Parse update: %#v", err)
+		rw.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	tgapi.Hash(upd)
+	if err := engine.Dispatch(ctx, w.Engine, upd); err != nil {
+		logging.S(ctx).Errorf("Error processing update: %#v", err)
+	}
+	rw.WriteHeader(http.StatusOK)
+}
@@ -0,0 +1,260 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/baldisbk/tgbot/pkg/engine"
+	"github.com/baldisbk/tgbot/pkg/tgapi"
+)
+
+func TestWebhook(t *testing.T) {
+	testCases := []struct {
+		desc           string
+		update         tgapi.Update
+		body           []byte // raw one instead of update
+		secret, header string
+		status         int
+	}{
+		{
+			desc: "message",
+			update: tgapi.Update{
+				Message: &tgapi.Message{Text: "text"},
+			},
+			status: http.StatusOK,
+		},
+		{
+			desc: "call",
+			update: tgapi.Update{
+				CallbackQuery: &tgapi.CallbackQuery{Data: "data"},
+			},
+			status: http.StatusOK,
+		},
+		{
+			desc: "secret",
+			update: tgapi.Update{
+				Message: &tgapi.Message{Text: "text"},
+			},
+			secret: "secret",
+			header: "secret",
+			status: http.StatusOK,
+		},
+		{
+			desc: "bad secret",
+			update: tgapi.Update{
+				Message: &tgapi.Message{Text: "text"},
+			},
+			secret: "secret",
+			header: "wrong",
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc: "no secret",
+			update: tgapi.Update{
+				Message: &tgapi.Message{Text: "text"},
+			},
+			secret: "secret",
+			status: http.StatusUnauthorized,
+		},
+		{
+			desc:   "too large",
+			body:   append(bytes.Repeat([]byte(" "), maxUpdateSize), []byte("{}")...),
+			status: http.StatusRequestEntityTooLarge,
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert := require.New(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			engine := engine.NewEngineMock()
+			tgClient := tgapi.NewMock()
+			tgClient.On(
+				"SetWebhook",
+				mock.Anything,
+				tgapi.SetWebhook{URL: "https://bot.example.com/hook", SecretToken: tC.secret},
+			).Return(nil).Once()
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.NoError(err)
+			cfg := Config{URL: "https://bot.example.com/hook", SecretToken: tC.secret}
+			hook, err := newWebhook(ctx, cfg, listener, tgClient, engine)
+			assert.NoError(err)
+			defer hook.Shutdown()
+			tgClient.AssertExpectations(t)
+
+			accepted := tC.status == http.StatusOK
+			if accepted && tC.update.Message != nil {
+				engine.On(
+					"Receive",
+					mock.Anything,
+					mock.MatchedBy(func(e *tgapi.Message) bool { return e.Text == "text" }),
+				).Return(nil).Once()
+			}
+			if accepted && tC.update.CallbackQuery != nil {
+				engine.On(
+					"Receive",
+					mock.Anything,
+					mock.MatchedBy(func(e *tgapi.CallbackQuery) bool { return e.Data == "data" }),
+				).Return(nil).Once()
+			}
+			body := tC.body
+			if body == nil {
+				body, err = json.Marshal(tC.update)
+				assert.NoError(err)
+			}
+			req, err := http.NewRequest(http.MethodPost, "http://"+listener.Addr().String()+"/hook", bytes.NewBuffer(body))
+			assert.NoError(err)
+			if tC.header != "" {
+				req.Header.Set(secretTokenHeader, tC.header)
+			}
+			rsp, err := http.DefaultClient.Do(req)
+			assert.NoError(err)
+			rsp.Body.Close()
+			assert.Equal(tC.status, rsp.StatusCode)
+			// engine called only if accepted
+			engine.AssertExpectations(t)
+		})
+	}
+}
+
+// selfSigned writes certificate and key for 127.0.0.1
+func selfSigned(t *testing.T) (certFile, keyFile string, pool *x509.CertPool) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDer, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	pool = x509.NewCertPool()
+	pool.AddCert(cert)
+
+	dir := t.TempDir()
+	certFile, keyFile = filepath.Join(dir, "cert.pem"), filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDer}), 0600))
+	return certFile, keyFile, pool
+}
+
+func TestWebhookSelfSigned(t *testing.T) {
+	assert := require.New(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	certFile, keyFile, pool := selfSigned(t)
+	cert, err := os.ReadFile(certFile)
+	assert.NoError(err)
+
+	engine := engine.NewEngineMock()
+	tgClient := tgapi.NewMock()
+	tgClient.On(
+		"SetWebhook",
+		mock.Anything,
+		tgapi.SetWebhook{URL: "https://bot.example.com/hook", Certificate: string(cert)},
+	).Return(nil).Once()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(err)
+	cfg := Config{URL: "https://bot.example.com/hook", CertFile: certFile, KeyFile: keyFile, SelfSigned: true}
+	hook, err := newWebhook(ctx, cfg, listener, tgClient, engine)
+	assert.NoError(err)
+	defer hook.Shutdown()
+	tgClient.AssertExpectations(t)
+
+	engine.On(
+		"Receive",
+		mock.Anything,
+		mock.MatchedBy(func(e *tgapi.Message) bool { return e.Text == "text" }),
+	).Return(nil).Once()
+	body, err := json.Marshal(tgapi.Update{Message: &tgapi.Message{Text: "text"}})
+	assert.NoError(err)
+	client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}}}
+	rsp, err := client.Post("https://"+listener.Addr().String()+"/hook", "application/json", bytes.NewBuffer(body))
+	assert.NoError(err)
+	rsp.Body.Close()
+	assert.Equal(http.StatusOK, rsp.StatusCode)
+	engine.AssertExpectations(t)
+}
+
+func TestWebhookBadTLS(t *testing.T) {
+	certFile, keyFile, _ := selfSigned(t)
+	otherCert, _, _ := selfSigned(t)
+	testCases := []struct {
+		desc string
+		cfg  Config
+	}{
+		{
+			desc: "self signed without files",
+			cfg:  Config{SelfSigned: true},
+		},
+		{
+			desc: "no key",
+			cfg:  Config{CertFile: certFile, SelfSigned: true},
+		},
+		{
+			desc: "no cert",
+			cfg:  Config{KeyFile: keyFile},
+		},
+		{
+			desc: "missing key",
+			cfg:  Config{CertFile: certFile, KeyFile: keyFile + ".missing"},
+		},
+		{
+			desc: "mismatched pair",
+			cfg:  Config{CertFile: otherCert, KeyFile: keyFile, SelfSigned: true},
+		},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.desc, func(t *testing.T) {
+			assert := require.New(t)
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			tgClient := tgapi.NewMock()
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			assert.NoError(err)
+			defer listener.Close()
+			tC.cfg.URL = "https://bot.example.com/hook"
+			_, err = newWebhook(ctx, tC.cfg, listener, tgClient, engine.NewEngineMock())
+			assert.Error(err)
+			// webhook is not registered
+			tgClient.AssertNotCalled(t, "SetWebhook", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestConfigGoString(t *testing.T) {
+	cfg := Config{URL: "https://bot.example.com/hook", SecretToken: "hooksecret"}
+	require.NotContains(t, fmt.Sprintf("%#v", struct{ Webhook Config }{cfg}), "hooksecret")
+}